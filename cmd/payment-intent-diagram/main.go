@@ -0,0 +1,26 @@
+// Command payment-intent-diagram renders the PaymentIntent transition table as Mermaid or Graphviz DOT.
+//
+//	go run ./cmd/payment-intent-diagram -format mermaid > docs/payment_intent_state_machine.mmd
+//	go run ./cmd/payment-intent-diagram -format dot | dot -Tsvg > payment_intent_state_machine.svg
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/converter"
+)
+
+func main() {
+	format := flag.String("format", string(converter.PaymentIntentDiagramFormatMermaid), "output format: mermaid or dot")
+	flag.Parse()
+
+	diagram, err := converter.ToPaymentIntentDiagram(converter.PaymentIntentDiagramFormat(*format), domain.PaymentIntentTransitions())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	fmt.Print(diagram)
+}
@@ -0,0 +1,153 @@
+// Command server exposes the checkout use cases over HTTP. State lives in memory and the payment provider is the
+// built-in stub, so it is meant for local development and demos.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/handler"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/usecase"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	confirmationNext := flag.String("confirmation-next", string(domain.PaymentConfirmationNextProcessing), "status the stub provider returns on confirmation")
+	maxFailedAttempts := flag.Uint("max-failed-attempts", 3, "failed payment attempts before an intent is canceled (0 = unlimited)")
+	webhookTolerance := flag.Duration("webhook-tolerance", iasvc.DefaultWebhookTolerance, "accepted age of a provider webhook signature")
+	inboxTTL := flag.Duration("webhook-inbox-ttl", domain.DefaultProviderEventInboxPolicy().TTL, "how long early provider events wait for their payment intent before they are orphaned (0 = forever)")
+	flag.Parse()
+	if *maxFailedAttempts > math.MaxUint8 {
+		slog.Error("max-failed-attempts is out of range", "value", *maxFailedAttempts, "max", math.MaxUint8)
+		os.Exit(2)
+	}
+
+	// シークレットはフラグに残さず環境変数から受け取る
+	webhookSecret := []byte(os.Getenv("WEBHOOK_SECRET"))
+	if len(webhookSecret) == 0 {
+		webhookSecret = []byte(rand.Text())
+		slog.Warn("WEBHOOK_SECRET is not set; provider webhooks will be rejected")
+	}
+	cartTokenSecret := []byte(os.Getenv("CART_TOKEN_SECRET"))
+	if len(cartTokenSecret) == 0 {
+		cartTokenSecret = []byte(rand.Text())
+		slog.Warn("CART_TOKEN_SECRET is not set; cart tokens will not survive a restart")
+	}
+	operatorToken := os.Getenv("OPERATOR_TOKEN")
+	if operatorToken == "" {
+		operatorToken = rand.Text()
+		slog.Warn("OPERATOR_TOKEN is not set; operator endpoints will be rejected")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newHandler(domain.PaymentConfirmationNext(*confirmationNext), uint8(*maxFailedAttempts), webhookSecret, *webhookTolerance, cartTokenSecret, domain.ProviderEventInboxPolicy{TTL: *inboxTTL}, operatorToken),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("listening", "addr", *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+func newHandler(
+	confirmationNext domain.PaymentConfirmationNext,
+	maxFailedAttempts uint8,
+	webhookSecret []byte,
+	webhookTolerance time.Duration,
+	cartTokenSecret []byte,
+	inboxPolicy domain.ProviderEventInboxPolicy,
+	operatorToken string,
+) http.Handler {
+	businessRepo := iarepo.NewInMemoryBusinessRepository()
+	paymentIntentRepo := iarepo.NewInMemoryPaymentIntentRepository()
+	cartRepo := iarepo.NewInMemoryCartRepository()
+	tokenService := iasvc.NewTokenService(cartTokenSecret)
+	// 決済手段ごとに別のアクワイアラを使う。スタブなので中身は同じ
+	paymentProviders := iasvc.NewPaymentMethodProviderRegistry(iasvc.PaymentMethodProviders{
+		domain.PaymentMethodTypeCard:   iasvc.NewPaymentMethodProviderService(confirmationNext),
+		domain.PaymentMethodTypePayPay: iasvc.NewPaymentMethodProviderService(confirmationNext),
+	}, nil)
+	clock := iasvc.NewSystemClock()
+	attemptPolicy := domain.PaymentAttemptPolicy{MaxFailedAttempts: maxFailedAttempts}
+
+	// 外部通知の適用側は素のリポジトリを使い、こちらから進める側は保存直後に保留中の通知を再生する
+	providerEventRepo := iarepo.NewInMemoryProviderEventRepository()
+	pendingProviderEventRepo := iarepo.NewInMemoryPendingProviderEventRepository()
+	eventHandlers := usecase.PaymentIntentEventHandlers{
+		ActionResult:     usecase.NewHandlePaymentActionResultUseCase(paymentIntentRepo, clock),
+		PaymentSucceeded: usecase.NewHandlePaymentSucceededUseCase(paymentIntentRepo),
+		PaymentFailed:    usecase.NewHandlePaymentFailedUseCase(paymentIntentRepo, attemptPolicy, clock),
+		CaptureFailed:    usecase.NewHandleCaptureFailedUseCase(paymentIntentRepo, clock),
+		RefundSucceeded:  usecase.NewHandleRefundSucceededUseCase(paymentIntentRepo),
+		RefundFailed:     usecase.NewHandleRefundFailedUseCase(paymentIntentRepo),
+	}
+	inboxRepo := usecase.NewPaymentIntentRepositoryWithInbox(
+		paymentIntentRepo,
+		usecase.NewReplayPendingProviderEventsUseCase(providerEventRepo, pendingProviderEventRepo, paymentIntentRepo, eventHandlers, inboxPolicy, clock),
+	)
+
+	return handler.NewHandler(handler.UseCases{
+		CreateBusiness: usecase.NewCreateBusinessUseCase(iasvc.NewRandomBusinessIDGenerator(), businessRepo),
+		CreateCart:     usecase.NewCreateCartUseCase(iasvc.NewRandomCartIDGenerator(), cartRepo),
+		ConfirmCart:    usecase.NewConfirmCartUseCase(tokenService, cartRepo),
+		InitializePaymentIntent: usecase.NewInitializePaymentIntentUseCase(
+			tokenService,
+			inboxRepo,
+			iasvc.NewRandomPaymentIntentIDGenerator(),
+			businessRepo,
+			clock,
+		),
+		SelectPaymentMethod:  usecase.NewSelectPaymentMethodUseCase(inboxRepo, clock),
+		ProvidePaymentMethod: usecase.NewProvidePaymentMethodUseCase(inboxRepo),
+		ConfirmPaymentIntent: usecase.NewConfirmPaymentIntentUseCase(
+			inboxRepo,
+			paymentProviders,
+			attemptPolicy,
+			clock,
+		),
+		CapturePaymentIntent: usecase.NewCapturePaymentIntentUseCase(
+			inboxRepo,
+			paymentProviders,
+			domain.PaymentOverCapturePolicy{},
+			domain.PaymentCaptureDeadlinePolicy{},
+			clock,
+		),
+		GetPaymentIntent: usecase.NewGetPaymentIntentUseCase(paymentIntentRepo),
+		ApplyPaymentIntentEvent: usecase.NewApplyPaymentIntentEventUseCase(
+			providerEventRepo,
+			pendingProviderEventRepo,
+			paymentIntentRepo,
+			eventHandlers,
+			inboxPolicy,
+			clock,
+		),
+		ListOrphanedProviderEvents: usecase.NewListOrphanedProviderEventsUseCase(pendingProviderEventRepo, inboxPolicy, clock),
+	}, handler.Webhook{
+		Verifier: iasvc.NewHMACWebhookVerifier(webhookSecret, webhookTolerance, clock),
+	}, handler.Operator{Token: operatorToken})
+}
@@ -15,11 +15,12 @@ type (
 	}
 )
 
-func NewBusinessID(id string) BusinessID {
-	if len(id) == 0 {
-		panic("invalid business id")
+func NewBusinessID(id string) (BusinessID, error) {
+	businessID := BusinessID(id)
+	if err := contract.Validate(businessID); err != nil {
+		return "", err
 	}
-	return BusinessID(id)
+	return businessID, nil
 }
 
 func (b BusinessID) Validate() error {
@@ -29,16 +30,20 @@ func (b BusinessID) Validate() error {
 	return nil
 }
 
-func NewBusiness(id BusinessID, name string, paymentMethodTypes PaymentMethodTypes) Business {
-	contract.AssertValidatable(id)
+func NewBusiness(id BusinessID, name string, paymentMethodTypes PaymentMethodTypes) (Business, error) {
+	if err := contract.Validate(id); err != nil {
+		return Business{}, err
+	}
 	if len(name) == 0 {
-		panic("invalid business name")
+		return Business{}, invalidArgument("business name is empty")
+	}
+	if err := contract.Validate(paymentMethodTypes); err != nil {
+		return Business{}, err
 	}
-	contract.AssertValidatable(paymentMethodTypes)
 
 	return Business{
 		ID:                 id,
 		Name:               name,
 		PaymentMethodTypes: paymentMethodTypes,
-	}
+	}, nil
 }
@@ -22,11 +22,12 @@ type (
 	}
 )
 
-func NewCartID(id string) CartID {
-	if len(id) == 0 {
-		panic("invalid cartID")
+func NewCartID(id string) (CartID, error) {
+	cartID := CartID(id)
+	if err := contract.Validate(cartID); err != nil {
+		return "", err
 	}
-	return CartID(id)
+	return cartID, nil
 }
 
 func (c CartID) Validate() error {
@@ -43,11 +44,12 @@ func (c CartItem) Validate() error {
 	return Money(c.Price).Validate()
 }
 
-func NewCartItems(items ...CartItem) CartItems {
-	if len(items) == 0 {
-		panic("invalid cartItems")
+func NewCartItems(items ...CartItem) (CartItems, error) {
+	cartItems := CartItems(items)
+	if err := contract.Validate(cartItems); err != nil {
+		return nil, err
 	}
-	return CartItems(items)
+	return cartItems, nil
 }
 
 func (c CartItems) Validate() error {
@@ -66,16 +68,16 @@ func NewCart(
 	businessID BusinessID,
 	cartID CartID,
 	items CartItems,
-) Cart {
-	contract.AssertValidatable(businessID)
-	contract.AssertValidatable(cartID)
-	contract.AssertValidatable(items)
-
-	return Cart{
+) (Cart, error) {
+	cart := Cart{
 		BusinessID: businessID,
 		CartID:     cartID,
 		Items:      items,
 	}
+	if err := contract.Validate(cart); err != nil {
+		return Cart{}, err
+	}
+	return cart, nil
 }
 
 func (c Cart) Validate() error {
@@ -88,6 +90,14 @@ func (c Cart) Validate() error {
 	if err := c.Items.Validate(); err != nil {
 		return err
 	}
+	var total Money
+	for _, item := range c.Items {
+		next, err := total.Add(Money(item.Price))
+		if err != nil {
+			return err
+		}
+		total = next
+	}
 	return nil
 }
 
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+)
+
+var (
+	ErrInvalidArgument        = contract.ErrInvalidArgument
+	ErrInvalidStateTransition = errors.New("invalid state transition")
+	ErrNotFound               = errors.New("not found")
+	ErrConflict               = errors.New("conflict")
+)
+
+type (
+	// NotFoundError reports a missing aggregate; errors.Is(err, ErrNotFound) holds.
+	NotFoundError struct {
+		Resource string
+		ID       string
+	}
+)
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found: %s", e.Resource, e.ID)
+}
+
+func (e NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func NewPaymentIntentNotFoundError(id PaymentIntentID) error {
+	return NotFoundError{Resource: "payment intent", ID: string(id)}
+}
+
+func NewBusinessNotFoundError(id BusinessID) error {
+	return NotFoundError{Resource: "business", ID: string(id)}
+}
+
+func NewCartNotFoundError(id CartID) error {
+	return NotFoundError{Resource: "cart", ID: string(id)}
+}
+
+func invalidArgument(message string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidArgument, message)
+}
+
+func invalidStateTransition(message string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidStateTransition, message)
+}
@@ -1,16 +1,21 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+)
 
 type (
-	Money uint8
+	Money uint64
 )
 
-func NewMoney(money uint8) Money {
-	if money == 0 {
-		panic("money cannot be zero")
+func NewMoney(money uint64) (Money, error) {
+	m := Money(money)
+	if err := contract.Validate(m); err != nil {
+		return 0, err
 	}
-	return Money(money)
+	return m, nil
 }
 
 func (m Money) Validate() error {
@@ -19,3 +24,11 @@ func (m Money) Validate() error {
 	}
 	return nil
 }
+
+func (m Money) Add(other Money) (Money, error) {
+	total := m + other
+	if total < m {
+		return 0, invalidArgument("money overflow")
+	}
+	return total, nil
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type (
+	PaymentAttemptOutcome string
+
+	// PaymentAttempt records one confirmation attempt. PaymentMethod is masked so that the history can be shown to support.
+	PaymentAttempt struct {
+		PaymentMethodType PaymentMethodType
+		PaymentMethod     PaymentMethod
+		Outcome           PaymentAttemptOutcome
+		FailureReason     PaymentFailureReason
+		ProviderErrorCode string
+		DeclineCategory   PaymentDeclineCategory
+		CustomerMessage   string
+		AttemptedAt       time.Time
+	}
+
+	PaymentAttempts []PaymentAttempt
+
+	// PaymentAttemptPolicy cancels an intent once it has failed MaxFailedAttempts times; zero means unlimited.
+	PaymentAttemptPolicy struct {
+		MaxFailedAttempts uint8
+	}
+)
+
+const (
+	PaymentAttemptOutcomeSucceeded      PaymentAttemptOutcome = "succeeded"
+	PaymentAttemptOutcomeRequiresAction PaymentAttemptOutcome = "requires_action"
+	PaymentAttemptOutcomeFailed         PaymentAttemptOutcome = "failed"
+)
+
+func (p PaymentAttemptOutcome) Validate() error {
+	switch p {
+	case PaymentAttemptOutcomeSucceeded,
+		PaymentAttemptOutcomeRequiresAction,
+		PaymentAttemptOutcomeFailed:
+		return nil
+	default:
+		return errors.New("unsupported payment attempt outcome")
+	}
+}
+
+func newPaymentAttempt(method PaymentMethod, outcome PaymentAttemptOutcome, attemptedAt time.Time) PaymentAttempt {
+	return PaymentAttempt{
+		PaymentMethodType: method.PaymentMethodType,
+		PaymentMethod:     method.Masked(),
+		Outcome:           outcome,
+		AttemptedAt:       attemptedAt,
+	}
+}
+
+func (p PaymentAttempts) Append(attempt PaymentAttempt) PaymentAttempts {
+	attempts := make(PaymentAttempts, 0, len(p)+1)
+	attempts = append(attempts, p...)
+	return append(attempts, attempt)
+}
+
+func (p PaymentAttempts) FailedCount() int {
+	count := 0
+	for _, attempt := range p {
+		if attempt.Outcome == PaymentAttemptOutcomeFailed {
+			count++
+		}
+	}
+	return count
+}
+
+// Validate accepts every limit because MaxFailedAttempts is unsigned and zero means unlimited.
+func (p PaymentAttemptPolicy) Validate() error {
+	return nil
+}
+
+func (p PaymentAttemptPolicy) IsExhausted(attempts PaymentAttempts) bool {
+	return p.MaxFailedAttempts > 0 && attempts.FailedCount() >= int(p.MaxFailedAttempts)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "errors"
+
+type (
+	PaymentCancellationReason string
+
+	// PaymentCancellationInitiator is who asks for an explicit cancellation. It decides which reasons may be given;
+	// reasons such as authorization_expired belong to their own transitions and cannot be given by either.
+	PaymentCancellationInitiator string
+)
+
+const (
+	PaymentCancellationReasonRequestedByCustomer  PaymentCancellationReason = "requested_by_customer"
+	PaymentCancellationReasonAbandoned            PaymentCancellationReason = "abandoned"
+	PaymentCancellationReasonDuplicate            PaymentCancellationReason = "duplicate"
+	PaymentCancellationReasonFraudulent           PaymentCancellationReason = "fraudulent"
+	PaymentCancellationReasonAuthorizationExpired PaymentCancellationReason = "authorization_expired"
+	PaymentCancellationReasonExpired              PaymentCancellationReason = "expired"
+	PaymentCancellationReasonMaxAttemptsExceeded  PaymentCancellationReason = "max_attempts_exceeded"
+)
+
+func (p PaymentCancellationReason) Validate() error {
+	if len(p) == 0 {
+		return errors.New("payment cancellation reason is empty")
+	}
+
+	switch p {
+	case PaymentCancellationReasonRequestedByCustomer,
+		PaymentCancellationReasonAbandoned,
+		PaymentCancellationReasonDuplicate,
+		PaymentCancellationReasonFraudulent,
+		PaymentCancellationReasonAuthorizationExpired,
+		PaymentCancellationReasonExpired,
+		PaymentCancellationReasonMaxAttemptsExceeded:
+		return nil
+	default:
+		return errors.New("unsupported payment cancellation reason")
+	}
+}
+
+const (
+	PaymentCancellationInitiatorCustomer PaymentCancellationInitiator = "customer"
+	PaymentCancellationInitiatorSystem   PaymentCancellationInitiator = "system"
+)
+
+func (p PaymentCancellationInitiator) Validate() error {
+	switch p {
+	case PaymentCancellationInitiatorCustomer, PaymentCancellationInitiatorSystem:
+		return nil
+	case "":
+		return errors.New("payment cancellation initiator is empty")
+	default:
+		return errors.New("unsupported payment cancellation initiator")
+	}
+}
+
+// Allows reports whether the initiator may cancel for reason. Only the system decides that an intent was abandoned.
+func (p PaymentCancellationInitiator) Allows(reason PaymentCancellationReason) bool {
+	switch reason {
+	case PaymentCancellationReasonRequestedByCustomer,
+		PaymentCancellationReasonDuplicate,
+		PaymentCancellationReasonFraudulent:
+		return true
+	case PaymentCancellationReasonAbandoned:
+		return p == PaymentCancellationInitiatorSystem
+	default:
+		return false
+	}
+}
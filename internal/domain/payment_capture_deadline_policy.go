@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+const DefaultPaymentCaptureWindow = 7 * 24 * time.Hour
+
+// PaymentCaptureDeadlinePolicy decides how long an authorization can be captured after it was authorized.
+// Payment method types without an explicit window fall back to DefaultPaymentCaptureWindow.
+type PaymentCaptureDeadlinePolicy struct {
+	Windows map[PaymentMethodType]time.Duration
+}
+
+func (p PaymentCaptureDeadlinePolicy) WindowFor(methodType PaymentMethodType) time.Duration {
+	if window, ok := p.Windows[methodType]; ok && window > 0 {
+		return window
+	}
+	return DefaultPaymentCaptureWindow
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "errors"
+
+type (
+	PaymentDeclineCategory string
+
+	// PaymentDecline is the provider's structured answer to a declined confirmation or capture.
+	PaymentDecline struct {
+		Code            string
+		Category        PaymentDeclineCategory
+		Retryable       bool
+		CustomerMessage string
+	}
+)
+
+const (
+	PaymentDeclineCategoryInsufficientFunds      PaymentDeclineCategory = "insufficient_funds"
+	PaymentDeclineCategoryExpiredCard            PaymentDeclineCategory = "expired_card"
+	PaymentDeclineCategoryDoNotHonor             PaymentDeclineCategory = "do_not_honor"
+	PaymentDeclineCategoryAuthenticationRequired PaymentDeclineCategory = "authentication_required"
+	PaymentDeclineCategoryProcessingError        PaymentDeclineCategory = "processing_error"
+)
+
+func (p PaymentDeclineCategory) Validate() error {
+	switch p {
+	case PaymentDeclineCategoryInsufficientFunds,
+		PaymentDeclineCategoryExpiredCard,
+		PaymentDeclineCategoryDoNotHonor,
+		PaymentDeclineCategoryAuthenticationRequired,
+		PaymentDeclineCategoryProcessingError:
+		return nil
+	default:
+		return errors.New("unsupported payment decline category")
+	}
+}
+
+func (p PaymentDecline) Validate() error {
+	return p.Category.Validate()
+}
+
+// FailureReasonOr maps the decline category to a failure reason, falling back when the provider could not classify the error.
+func (p PaymentDecline) FailureReasonOr(fallback PaymentFailureReason) PaymentFailureReason {
+	switch p.Category {
+	case PaymentDeclineCategoryInsufficientFunds:
+		return PaymentFailureReasonInsufficientFunds
+	case PaymentDeclineCategoryExpiredCard:
+		return PaymentFailureReasonExpiredCard
+	case PaymentDeclineCategoryDoNotHonor:
+		return PaymentFailureReasonDoNotHonor
+	case PaymentDeclineCategoryAuthenticationRequired:
+		return PaymentFailureReasonAuthenticationRequired
+	case PaymentDeclineCategoryProcessingError:
+		return PaymentFailureReasonProcessingError
+	default:
+		return fallback
+	}
+}
@@ -7,6 +7,14 @@ type PaymentFailureReason string
 const (
 	PaymentFailureReasonConfirmationFailed PaymentFailureReason = "confirmation_failed"
 	PaymentFailureReasonCaptureFailed      PaymentFailureReason = "capture_failed"
+	PaymentFailureReasonPaymentFailed      PaymentFailureReason = "payment_failed"
+	PaymentFailureReasonRefundFailed       PaymentFailureReason = "refund_failed"
+
+	PaymentFailureReasonInsufficientFunds      PaymentFailureReason = "insufficient_funds"
+	PaymentFailureReasonExpiredCard            PaymentFailureReason = "expired_card"
+	PaymentFailureReasonDoNotHonor             PaymentFailureReason = "do_not_honor"
+	PaymentFailureReasonAuthenticationRequired PaymentFailureReason = "authentication_required"
+	PaymentFailureReasonProcessingError        PaymentFailureReason = "processing_error"
 )
 
 func (p PaymentFailureReason) Validate() error {
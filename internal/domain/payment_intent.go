@@ -1,25 +1,36 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type (
 	PaymentIntentID string
 
 	PaymentIntent interface {
-		RequirePaymentMethod(PaymentMethodType) (PaymentIntentEvent, PaymentIntent, error)
+		Status() PaymentIntentStatus
+		RequirePaymentMethod(PaymentMethodType, time.Time) (PaymentIntentEvent, PaymentIntent, error)
 		RequireConfirmation(PaymentMethod, PaymentCaptureMethod) (PaymentIntentEvent, PaymentIntent, error)
-		RequireAction() (PaymentIntentEvent, PaymentIntent, error)
-		RequireCapture() (PaymentIntentEvent, PaymentIntent, error)
+		RequireAction(time.Time) (PaymentIntentEvent, PaymentIntent, error)
+		RequireCapture(time.Time) (PaymentIntentEvent, PaymentIntent, error)
 		StartProcessing() (PaymentIntentEvent, PaymentIntent, error)
 		Complete() (PaymentIntentEvent, PaymentIntent, error)
-		Fail(PaymentFailureReason, bool) (PaymentIntentEvent, PaymentIntent, error)
+		Fail(PaymentFailureReason, bool, time.Time) (PaymentIntentEvent, PaymentIntent, error)
+		Cancel(PaymentCancellationReason) (PaymentIntentEvent, PaymentIntent, error)
 	}
 
 	paymentIntentMeta struct {
-		ID      PaymentIntentID
-		SeqNr   uint8
-		Version uint8
-		Amount  Money
+		ID         PaymentIntentID
+		SeqNr      uint64
+		Version    uint8
+		Amount     Money
+		BusinessID BusinessID
+		CartID     CartID
+		CartItems  CartItems
+		CreatedAt  time.Time
+		// Attempts は決済手段を選び直しても引き継がれる
+		Attempts PaymentAttempts
 	}
 )
 
@@ -30,30 +41,7 @@ func (p PaymentIntentID) Validate() error {
 	return nil
 }
 
-func (p paymentIntentMeta) RequirePaymentMethod(methodType PaymentMethodType) (PaymentIntentEvent, PaymentIntent, error) {
-	panic("intentionally unimplemented; concrete states must override as needed")
-}
-
-func (p paymentIntentMeta) RequireConfirmation(method PaymentMethod, captureMethod PaymentCaptureMethod) (PaymentIntentEvent, PaymentIntent, error) {
-	panic("intentionally unimplemented; concrete states must override as needed")
-}
-
-func (p paymentIntentMeta) RequireAction() (PaymentIntentEvent, PaymentIntent, error) {
-	panic("intentionally unimplemented; concrete states must override as needed")
-}
-
-func (p paymentIntentMeta) RequireCapture() (PaymentIntentEvent, PaymentIntent, error) {
-	panic("intentionally unimplemented; concrete states must override as needed")
-}
-
-func (p paymentIntentMeta) StartProcessing() (PaymentIntentEvent, PaymentIntent, error) {
-	panic("intentionally unimplemented; concrete states must override as needed")
-}
-
-func (p paymentIntentMeta) Complete() (PaymentIntentEvent, PaymentIntent, error) {
-	panic("intentionally unimplemented; concrete states must override as needed")
-}
-
-func (p paymentIntentMeta) Fail(reason PaymentFailureReason, retryable bool) (PaymentIntentEvent, PaymentIntent, error) {
-	panic("intentionally unimplemented; concrete states must override as needed")
+func (p paymentIntentMeta) next() paymentIntentMeta {
+	p.SeqNr++
+	return p
 }
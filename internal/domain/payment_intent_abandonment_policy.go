@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// PaymentIntentAbandonmentPolicy decides when an intent that is still waiting on the customer counts as an abandoned checkout.
+// Timeouts are measured from when the intent entered the state; states without a timeout never expire.
+type PaymentIntentAbandonmentPolicy struct {
+	Timeouts map[PaymentIntentStatus]time.Duration
+}
+
+func DefaultPaymentIntentAbandonmentPolicy() PaymentIntentAbandonmentPolicy {
+	return PaymentIntentAbandonmentPolicy{
+		Timeouts: map[PaymentIntentStatus]time.Duration{
+			PaymentIntentStatusRequiresPaymentMethodType: time.Hour,
+			PaymentIntentStatusRequiresPaymentMethod:     24 * time.Hour,
+			PaymentIntentStatusRequiresAction:            time.Hour,
+		},
+	}
+}
+
+func (p PaymentIntentAbandonmentPolicy) Validate() error {
+	for status, timeout := range p.Timeouts {
+		switch status {
+		case PaymentIntentStatusRequiresPaymentMethodType,
+			PaymentIntentStatusRequiresPaymentMethod,
+			PaymentIntentStatusRequiresAction:
+		default:
+			return errors.New("abandonment timeout is only supported for states waiting on the customer")
+		}
+		if timeout <= 0 {
+			return errors.New("abandonment timeout must be positive")
+		}
+	}
+	return nil
+}
+
+func (p PaymentIntentAbandonmentPolicy) Statuses() []PaymentIntentStatus {
+	statuses := make([]PaymentIntentStatus, 0, len(p.Timeouts))
+	for status := range p.Timeouts {
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func (p PaymentIntentAbandonmentPolicy) IsAbandoned(status PaymentIntentStatus, statusChangedAt time.Time, now time.Time) bool {
+	timeout, ok := p.Timeouts[status]
+	if !ok {
+		return false
+	}
+	return !now.Before(statusChangedAt.Add(timeout))
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type (
 	PaymentIntentEvent interface {
 		PaymentIntentEvent()
@@ -7,19 +9,24 @@ type (
 
 	paymentIntentEventMeta struct {
 		PaymentIntentID PaymentIntentID
-		SeqNr           uint8
+		SeqNr           uint64
 	}
 
 	PaymentIntentRequiresPaymentMethodTypeEvent struct {
 		paymentIntentEventMeta
+		BusinessID         BusinessID
+		CartID             CartID
+		CartItems          CartItems
 		PaymentMethodTypes PaymentMethodTypes
 		Amount             Money
+		CreatedAt          time.Time
 	}
 
 	PaymentIntentRequiresPaymentMethodEvent struct {
 		paymentIntentEventMeta
 		PaymentMethodType PaymentMethodType
 		Amount            Money
+		StatusChangedAt   time.Time
 	}
 
 	PaymentIntentRequiresConfirmationEvent struct {
@@ -31,8 +38,10 @@ type (
 
 	PaymentIntentRequiresActionEvent struct {
 		paymentIntentEventMeta
-		PaymentMethod PaymentMethod
-		Amount        Money
+		PaymentMethod   PaymentMethod
+		Amount          Money
+		StatusChangedAt time.Time
+		Attempt         *PaymentAttempt
 	}
 
 	PaymentIntentRequiresCaptureEvent struct {
@@ -40,19 +49,76 @@ type (
 		PaymentMethod PaymentMethod
 		CaptureMethod PaymentCaptureMethod
 		Amount        Money
+		AuthorizedAt  time.Time
+		Attempt       *PaymentAttempt
+	}
+
+	PaymentIntentPartiallyCapturedEvent struct {
+		paymentIntentEventMeta
+		PaymentMethod  PaymentMethod
+		Amount         Money
+		CaptureAmount  Money
+		AmountCaptured Money
+	}
+
+	PaymentIntentPaymentMethodTypeReselectedEvent struct {
+		paymentIntentEventMeta
+		PreviousPaymentMethodType PaymentMethodType
+		PaymentMethodTypes        PaymentMethodTypes
+		Amount                    Money
+		StatusChangedAt           time.Time
+	}
+
+	PaymentIntentAmountChangedEvent struct {
+		paymentIntentEventMeta
+		CartItems      CartItems
+		PreviousAmount Money
+		Amount         Money
+	}
+
+	PaymentIntentAmountIncrementedEvent struct {
+		paymentIntentEventMeta
+		PaymentMethod   PaymentMethod
+		PreviousAmount  Money
+		IncrementAmount Money
+		Amount          Money
 	}
 
 	PaymentIntentProcessingEvent struct {
 		paymentIntentEventMeta
-		PaymentMethod PaymentMethod
-		CaptureMethod PaymentCaptureMethod
-		Amount        Money
+		PaymentMethod  PaymentMethod
+		CaptureMethod  PaymentCaptureMethod
+		Amount         Money
+		CaptureAmount  Money
+		AmountCaptured Money
+		AmountReleased Money
+		Attempt        *PaymentAttempt
 	}
 
 	PaymentIntentCompleteEvent struct {
 		paymentIntentEventMeta
-		PaymentMethod PaymentMethod
-		Amount        Money
+		PaymentMethod  PaymentMethod
+		Amount         Money
+		AmountCaptured Money
+	}
+
+	PaymentIntentRefundRequestedEvent struct {
+		paymentIntentEventMeta
+		RefundID PaymentRefundID
+		Amount   Money
+	}
+
+	PaymentIntentRefundSucceededEvent struct {
+		paymentIntentEventMeta
+		RefundID PaymentRefundID
+		Amount   Money
+	}
+
+	PaymentIntentRefundFailedEvent struct {
+		paymentIntentEventMeta
+		RefundID PaymentRefundID
+		Amount   Money
+		Reason   PaymentFailureReason
 	}
 
 	PaymentIntentFailedEvent struct {
@@ -61,16 +127,29 @@ type (
 		PaymentMethod     PaymentMethod
 		Amount            Money
 		Reason            PaymentFailureReason
+		StatusChangedAt   time.Time
+		Attempt           *PaymentAttempt
 	}
 
 	PaymentIntentCanceledEvent struct {
 		paymentIntentEventMeta
-		PaymentMethod PaymentMethod
-		Amount        Money
-		Reason        PaymentFailureReason
+		PaymentMethod      PaymentMethod
+		Amount             Money
+		Reason             PaymentFailureReason
+		CancellationReason PaymentCancellationReason
+		Attempt            *PaymentAttempt
 	}
 )
 
 func (e paymentIntentEventMeta) PaymentIntentEvent() {
 	panic("do not call this method")
 }
+
+func (e paymentIntentEventMeta) eventMeta() paymentIntentEventMeta {
+	return e
+}
+
+// PaymentIntentIDOf returns the aggregate the event belongs to.
+func PaymentIntentIDOf(event PaymentIntentEvent) PaymentIntentID {
+	return event.(interface{ eventMeta() paymentIntentEventMeta }).eventMeta().PaymentIntentID
+}
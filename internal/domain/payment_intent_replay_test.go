@@ -0,0 +1,402 @@
+package domain
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayPaymentIntent_ShouldRejectBrokenStreams(t *testing.T) {
+	created, intent, err := GeneratePaymentIntent(
+		"pi_replay",
+		Cart{BusinessID: "biz_replay", CartID: "cart_replay", Items: CartItems{{ItemID: "item_1", Price: 120}}},
+		PaymentMethodTypes{PaymentMethodTypeCard},
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	selected, _, err := intent.RequirePaymentMethod(PaymentMethodTypeCard, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	_, err = replayPaymentIntent(nil)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = replayPaymentIntent([]PaymentIntentEvent{selected})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = replayPaymentIntent([]PaymentIntentEvent{created, selected, selected})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = replayPaymentIntent([]PaymentIntentEvent{created, created})
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestReplayPaymentIntent_ShouldRebuildSavedState(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	card := PaymentMethod{
+		PaymentMethodType: PaymentMethodTypeCard,
+		Card:              &PaymentMethodCard{Number: "4242424242424242", ExpYear: 25, ExpMonth: 12},
+	}
+	cart := Cart{BusinessID: "biz_replay", CartID: "cart_replay", Items: CartItems{{ItemID: "item_1", Price: 120}}}
+	updatedCart := Cart{BusinessID: "biz_replay", CartID: "cart_replay", Items: CartItems{{ItemID: "item_1", Price: 150}}}
+	type step func(PaymentIntent) (PaymentIntentEvent, PaymentIntent, error)
+
+	selectCard := func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+		return p.(PaymentIntentRequiresPaymentMethodType).RequirePaymentMethod(PaymentMethodTypeCard, now)
+	}
+	provideCard := func(captureMethod PaymentCaptureMethod) step {
+		return func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+			return p.(PaymentIntentRequiresPaymentMethod).RequireConfirmation(card, captureMethod)
+		}
+	}
+	confirm := func(next PaymentConfirmationNext) step {
+		return func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+			return p.(PaymentIntentRequiresConfirmation).ApplyConfirmationResult(next, now)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "partial captures and refunds",
+			steps: []step{
+				selectCard,
+				provideCard(PaymentCaptureMethodManual),
+				confirm(PaymentConfirmationNextRequiresCapture),
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentRequiresCapture).IncrementAuthorization(30)
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentRequiresCapture).CapturePartially(50)
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentRequiresCapture).Capture(60, PaymentOverCapturePolicy{})
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentProcessing).Complete()
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentSucceeded).RequestRefund("re_1", 40)
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentSucceeded).SucceedRefund("re_1")
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentSucceeded).RequestRefund("re_2", 20)
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentSucceeded).FailRefund("re_2", PaymentFailureReasonPaymentFailed)
+				},
+			},
+		},
+		{
+			name: "failed next action and reselection",
+			steps: []step{
+				selectCard,
+				provideCard(PaymentCaptureMethodAutomatic),
+				confirm(PaymentConfirmationNextRequiresAction),
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentRequiresAction).FailPayment(PaymentDecline{}, now, PaymentAttemptPolicy{})
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentRequiresPaymentMethod).ReselectPaymentMethodType(PaymentMethodTypes{PaymentMethodTypeCard}, now)
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentRequiresPaymentMethodType).UpdateAmount(updatedCart)
+				},
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentRequiresPaymentMethodType).Cancel(PaymentCancellationReasonRequestedByCustomer)
+				},
+			},
+		},
+		{
+			name: "terminal decline on confirmation",
+			steps: []step{
+				selectCard,
+				provideCard(PaymentCaptureMethodAutomatic),
+				func(p PaymentIntent) (PaymentIntentEvent, PaymentIntent, error) {
+					return p.(PaymentIntentRequiresConfirmation).FailConfirmation(
+						PaymentDecline{Code: "do_not_honor", Category: PaymentDeclineCategoryDoNotHonor},
+						now,
+						PaymentAttemptPolicy{},
+					)
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, current, err := GeneratePaymentIntent("pi_replay", cart, PaymentMethodTypes{PaymentMethodTypeCard}, now)
+			require.NoError(t, err)
+			events := []PaymentIntentEvent{created}
+			for _, next := range tt.steps {
+				event, aggregate, err := next(current)
+				require.NoError(t, err)
+				events = append(events, event)
+				current = aggregate
+
+				replayed, err := replayPaymentIntent(events)
+				require.NoError(t, err)
+				assert.Equal(t, current, replayed)
+				assertAmountInSync(t, current)
+			}
+		})
+	}
+}
+
+func (p paymentIntentMeta) meta() paymentIntentMeta {
+	return p
+}
+
+// assertAmountInSync checks that a state's own Amount and the Amount in its embedded paymentIntentMeta agree. Comparing
+// saved and replayed aggregates cannot catch drift between the two when the replay carries the same mistake.
+func assertAmountInSync(t *testing.T, p PaymentIntent) {
+	t.Helper()
+
+	amount := reflect.ValueOf(p).FieldByName("Amount")
+	require.True(t, amount.IsValid(), "%T has no Amount", p)
+	assert.Equal(t, p.(interface{ meta() paymentIntentMeta }).meta().Amount, Money(amount.Uint()), "%T", p)
+}
+
+// replayPaymentIntent rebuilds an aggregate from its event stream. It mirrors the transitions in payment_intent_state.go,
+// so the result must equal the aggregate that was saved together with the last event.
+func replayPaymentIntent(events []PaymentIntentEvent) (PaymentIntent, error) {
+	if len(events) == 0 {
+		return nil, invalidArgument("payment intent event stream is empty")
+	}
+
+	var current PaymentIntent
+	for _, event := range events {
+		next, err := applyPaymentIntentEvent(current, event)
+		if err != nil {
+			return nil, err
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func applyPaymentIntentEvent(current PaymentIntent, event PaymentIntentEvent) (PaymentIntent, error) {
+	if created, ok := event.(PaymentIntentRequiresPaymentMethodTypeEvent); ok {
+		if current != nil || created.SeqNr != 1 {
+			return nil, invalidArgument("payment intent can only be created by the first event")
+		}
+		return PaymentIntentRequiresPaymentMethodType{
+			paymentIntentMeta: paymentIntentMeta{
+				ID:         created.PaymentIntentID,
+				SeqNr:      created.SeqNr,
+				Amount:     created.Amount,
+				BusinessID: created.BusinessID,
+				CartID:     created.CartID,
+				CartItems:  created.CartItems,
+				CreatedAt:  created.CreatedAt,
+			},
+			PaymentMethodTypes: created.PaymentMethodTypes,
+			Amount:             created.Amount,
+			StatusChangedAt:    created.CreatedAt,
+		}, nil
+	}
+	if current == nil {
+		return nil, invalidArgument("payment intent event stream must start with creation")
+	}
+
+	meta := current.(interface{ meta() paymentIntentMeta }).meta()
+	eventMeta := event.(interface{ eventMeta() paymentIntentEventMeta }).eventMeta()
+	if eventMeta.PaymentIntentID != meta.ID || eventMeta.SeqNr != meta.SeqNr+1 {
+		return nil, invalidArgument("payment intent event is out of sequence")
+	}
+	next := meta.next()
+
+	switch e := event.(type) {
+	case PaymentIntentRequiresPaymentMethodEvent:
+		return PaymentIntentRequiresPaymentMethod{
+			paymentIntentMeta: next,
+			PaymentMethodType: e.PaymentMethodType,
+			Amount:            e.Amount,
+			StatusChangedAt:   e.StatusChangedAt,
+		}, nil
+	case PaymentIntentRequiresConfirmationEvent:
+		return PaymentIntentRequiresConfirmation{
+			paymentIntentMeta: next,
+			PaymentMethod:     e.PaymentMethod,
+			CaptureMethod:     e.CaptureMethod,
+			Amount:            e.Amount,
+		}, nil
+	case PaymentIntentRequiresActionEvent:
+		prev, ok := current.(PaymentIntentRequiresConfirmation)
+		if !ok {
+			return nil, ErrInvalidTransition{From: current.Status(), Action: PaymentIntentActionRequireAction}
+		}
+		return PaymentIntentRequiresAction{
+			paymentIntentMeta: withReplayedAttempt(next, e.Attempt),
+			PaymentMethod:     e.PaymentMethod,
+			CaptureMethod:     prev.CaptureMethod,
+			Amount:            e.Amount,
+			StatusChangedAt:   e.StatusChangedAt,
+		}, nil
+	case PaymentIntentRequiresCaptureEvent:
+		return PaymentIntentRequiresCapture{
+			paymentIntentMeta: withReplayedAttempt(next, e.Attempt),
+			PaymentMethod:     e.PaymentMethod,
+			CaptureMethod:     e.CaptureMethod,
+			Amount:            e.Amount,
+			AuthorizedAt:      e.AuthorizedAt,
+		}, nil
+	case PaymentIntentPartiallyCapturedEvent:
+		prev, ok := current.(PaymentIntentRequiresCapture)
+		if !ok {
+			return nil, ErrInvalidTransition{From: current.Status(), Action: PaymentIntentActionCapturePartially}
+		}
+		return PaymentIntentRequiresCapture{
+			paymentIntentMeta: next,
+			PaymentMethod:     e.PaymentMethod,
+			CaptureMethod:     prev.CaptureMethod,
+			Amount:            e.Amount,
+			AmountCaptured:    e.AmountCaptured,
+			AuthorizedAt:      prev.AuthorizedAt,
+		}, nil
+	case PaymentIntentAmountIncrementedEvent:
+		prev, ok := current.(PaymentIntentRequiresCapture)
+		if !ok {
+			return nil, ErrInvalidTransition{From: current.Status(), Action: PaymentIntentActionIncrementAuthorization}
+		}
+		next.Amount = e.Amount
+		return PaymentIntentRequiresCapture{
+			paymentIntentMeta: next,
+			PaymentMethod:     e.PaymentMethod,
+			CaptureMethod:     prev.CaptureMethod,
+			Amount:            e.Amount,
+			AmountCaptured:    prev.AmountCaptured,
+			AuthorizedAt:      prev.AuthorizedAt,
+		}, nil
+	case PaymentIntentPaymentMethodTypeReselectedEvent:
+		return PaymentIntentRequiresPaymentMethodType{
+			paymentIntentMeta:  next,
+			PaymentMethodTypes: e.PaymentMethodTypes,
+			Amount:             e.Amount,
+			StatusChangedAt:    e.StatusChangedAt,
+		}, nil
+	case PaymentIntentAmountChangedEvent:
+		next.Amount = e.Amount
+		next.CartItems = e.CartItems
+		switch prev := current.(type) {
+		case PaymentIntentRequiresPaymentMethodType:
+			return PaymentIntentRequiresPaymentMethodType{
+				paymentIntentMeta:  next,
+				PaymentMethodTypes: prev.PaymentMethodTypes,
+				Amount:             e.Amount,
+				StatusChangedAt:    prev.StatusChangedAt,
+			}, nil
+		case PaymentIntentRequiresPaymentMethod:
+			return PaymentIntentRequiresPaymentMethod{
+				paymentIntentMeta: next,
+				PaymentMethodType: prev.PaymentMethodType,
+				Amount:            e.Amount,
+				FailureReason:     prev.FailureReason,
+				StatusChangedAt:   prev.StatusChangedAt,
+			}, nil
+		case PaymentIntentRequiresConfirmation:
+			return PaymentIntentRequiresConfirmation{
+				paymentIntentMeta: next,
+				PaymentMethod:     prev.PaymentMethod,
+				CaptureMethod:     prev.CaptureMethod,
+				Amount:            e.Amount,
+			}, nil
+		default:
+			return nil, ErrInvalidTransition{From: current.Status(), Action: PaymentIntentActionUpdateAmount}
+		}
+	case PaymentIntentProcessingEvent:
+		return PaymentIntentProcessing{
+			paymentIntentMeta: withReplayedAttempt(next, e.Attempt),
+			PaymentMethod:     e.PaymentMethod,
+			CaptureMethod:     e.CaptureMethod,
+			Amount:            e.Amount,
+			CaptureAmount:     e.CaptureAmount,
+			AmountCaptured:    e.AmountCaptured,
+		}, nil
+	case PaymentIntentCompleteEvent:
+		return PaymentIntentSucceeded{
+			paymentIntentMeta: next,
+			PaymentMethod:     e.PaymentMethod,
+			Amount:            e.Amount,
+			AmountCaptured:    e.AmountCaptured,
+		}, nil
+	case PaymentIntentRefundRequestedEvent, PaymentIntentRefundSucceededEvent, PaymentIntentRefundFailedEvent:
+		prev, ok := current.(PaymentIntentSucceeded)
+		if !ok {
+			return nil, ErrInvalidTransition{From: current.Status(), Action: PaymentIntentActionRequestRefund}
+		}
+		refunds, err := replayPaymentRefund(prev.Refunds, event)
+		if err != nil {
+			return nil, err
+		}
+		return PaymentIntentSucceeded{
+			paymentIntentMeta: next,
+			PaymentMethod:     prev.PaymentMethod,
+			Amount:            prev.Amount,
+			AmountCaptured:    prev.AmountCaptured,
+			Refunds:           refunds,
+		}, nil
+	case PaymentIntentFailedEvent:
+		return PaymentIntentRequiresPaymentMethod{
+			paymentIntentMeta: withReplayedAttempt(next, e.Attempt),
+			PaymentMethodType: e.PaymentMethodType,
+			Amount:            e.Amount,
+			FailureReason:     e.Reason,
+			StatusChangedAt:   e.StatusChangedAt,
+		}, nil
+	case PaymentIntentCanceledEvent:
+		return PaymentIntentCanceled{
+			paymentIntentMeta:  withReplayedAttempt(next, e.Attempt),
+			PaymentMethod:      e.PaymentMethod,
+			Amount:             e.Amount,
+			FailureReason:      e.Reason,
+			CancellationReason: e.CancellationReason,
+		}, nil
+	default:
+		return nil, invalidArgument("unsupported payment intent event")
+	}
+}
+
+func withReplayedAttempt(meta paymentIntentMeta, attempt *PaymentAttempt) paymentIntentMeta {
+	if attempt != nil {
+		meta.Attempts = meta.Attempts.Append(*attempt)
+	}
+	return meta
+}
+
+func replayPaymentRefund(refunds PaymentRefunds, event PaymentIntentEvent) (PaymentRefunds, error) {
+	switch e := event.(type) {
+	case PaymentIntentRefundRequestedEvent:
+		return append(slices.Clone(refunds), PaymentRefund{
+			ID:     e.RefundID,
+			Amount: e.Amount,
+			Status: PaymentRefundStatusPending,
+		}), nil
+	case PaymentIntentRefundSucceededEvent:
+		_, idx, exists := refunds.Find(e.RefundID)
+		if !exists {
+			return nil, NotFoundError{Resource: "refund", ID: string(e.RefundID)}
+		}
+		refunds = slices.Clone(refunds)
+		refunds[idx].Status = PaymentRefundStatusSucceeded
+		return refunds, nil
+	case PaymentIntentRefundFailedEvent:
+		_, idx, exists := refunds.Find(e.RefundID)
+		if !exists {
+			return nil, NotFoundError{Resource: "refund", ID: string(e.RefundID)}
+		}
+		refunds = slices.Clone(refunds)
+		refunds[idx].Status = PaymentRefundStatusFailed
+		refunds[idx].FailureReason = e.Reason
+		return refunds, nil
+	default:
+		return nil, invalidArgument("unsupported refund event")
+	}
+}
@@ -1,7 +1,9 @@
 package domain
 
 import (
-	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
 )
@@ -9,19 +11,24 @@ import (
 type (
 	PaymentIntentRequiresPaymentMethodType struct {
 		paymentIntentMeta
+		unsupportedTransitions[requiresPaymentMethodTypeStatus]
 		PaymentMethodTypes PaymentMethodTypes
 		Amount             Money
+		StatusChangedAt    time.Time
 	}
 
 	PaymentIntentRequiresPaymentMethod struct {
 		paymentIntentMeta
+		unsupportedTransitions[requiresPaymentMethodStatus]
 		PaymentMethodType PaymentMethodType
 		Amount            Money
 		FailureReason     PaymentFailureReason
+		StatusChangedAt   time.Time
 	}
 
 	PaymentIntentRequiresConfirmation struct {
 		paymentIntentMeta
+		unsupportedTransitions[requiresConfirmationStatus]
 		PaymentMethod PaymentMethod
 		CaptureMethod PaymentCaptureMethod
 		Amount        Money
@@ -29,67 +36,105 @@ type (
 
 	PaymentIntentRequiresAction struct {
 		paymentIntentMeta
-		PaymentMethod PaymentMethod
-		CaptureMethod PaymentCaptureMethod
-		Amount        Money
+		unsupportedTransitions[requiresActionStatus]
+		PaymentMethod   PaymentMethod
+		CaptureMethod   PaymentCaptureMethod
+		Amount          Money
+		StatusChangedAt time.Time
 	}
 
 	PaymentIntentRequiresCapture struct {
 		paymentIntentMeta
-		PaymentMethod PaymentMethod
-		CaptureMethod PaymentCaptureMethod
-		Amount        Money
+		unsupportedTransitions[requiresCaptureStatus]
+		PaymentMethod  PaymentMethod
+		CaptureMethod  PaymentCaptureMethod
+		Amount         Money
+		AmountCaptured Money
+		AuthorizedAt   time.Time
 	}
 
 	PaymentIntentProcessing struct {
 		paymentIntentMeta
+		unsupportedTransitions[processingStatus]
 		PaymentMethod PaymentMethod
 		CaptureMethod PaymentCaptureMethod
 		Amount        Money
+		// CaptureAmount is the capture the provider is still settling. AmountCaptured includes it together with the
+		// pieces that were already settled by earlier partial captures.
+		CaptureAmount  Money
+		AmountCaptured Money
 	}
 
 	PaymentIntentSucceeded struct {
 		paymentIntentMeta
-		PaymentMethod PaymentMethod
-		Amount        Money
+		unsupportedTransitions[succeededStatus]
+		PaymentMethod  PaymentMethod
+		Amount         Money
+		AmountCaptured Money
+		Refunds        PaymentRefunds
 	}
 
 	PaymentIntentCanceled struct {
 		paymentIntentMeta
-		PaymentMethod PaymentMethod
-		Amount        Money
-		FailureReason PaymentFailureReason
+		unsupportedTransitions[canceledStatus]
+		PaymentMethod      PaymentMethod
+		Amount             Money
+		FailureReason      PaymentFailureReason
+		CancellationReason PaymentCancellationReason
 	}
 )
 
-func GeneratePaymentIntent(id PaymentIntentID, types PaymentMethodTypes, amount Money) (PaymentIntentEvent, PaymentIntent, error) {
-	seqNr := uint8(1)
+func GeneratePaymentIntent(
+	id PaymentIntentID,
+	cart Cart,
+	types PaymentMethodTypes,
+	createdAt time.Time,
+) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := contract.Validate(id, cart); err != nil {
+		return nil, nil, err
+	}
+
+	seqNr := uint64(1)
+	amount := cart.CalculateAmount()
 
 	event := PaymentIntentRequiresPaymentMethodTypeEvent{
 		paymentIntentEventMeta: paymentIntentEventMeta{
 			PaymentIntentID: id,
 			SeqNr:           seqNr,
 		},
+		BusinessID:         cart.BusinessID,
+		CartID:             cart.CartID,
+		CartItems:          cart.Items,
 		PaymentMethodTypes: types,
 		Amount:             amount,
+		CreatedAt:          createdAt,
 	}
 
 	aggregate := PaymentIntentRequiresPaymentMethodType{
 		paymentIntentMeta: paymentIntentMeta{
-			ID:     id,
-			SeqNr:  seqNr,
-			Amount: amount,
+			ID:         id,
+			SeqNr:      seqNr,
+			Amount:     amount,
+			BusinessID: cart.BusinessID,
+			CartID:     cart.CartID,
+			CartItems:  cart.Items,
+			CreatedAt:  createdAt,
 		},
 		PaymentMethodTypes: types,
 		Amount:             amount,
+		StatusChangedAt:    createdAt,
 	}
 
 	return event, aggregate, nil
 }
 
-func (p PaymentIntentRequiresPaymentMethodType) RequirePaymentMethod(methodType PaymentMethodType) (PaymentIntentEvent, PaymentIntent, error) {
+func (p PaymentIntentRequiresPaymentMethodType) RequirePaymentMethod(methodType PaymentMethodType, selectedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionRequirePaymentMethod); err != nil {
+		return nil, nil, err
+	}
+
 	if !p.PaymentMethodTypes.Contains(methodType) {
-		return nil, nil, errors.New("payment method type not found in payment methods")
+		return nil, nil, invalidArgument("payment method type not found in payment methods")
 	}
 
 	seqNr := p.SeqNr + 1
@@ -101,27 +146,30 @@ func (p PaymentIntentRequiresPaymentMethodType) RequirePaymentMethod(methodType
 		},
 		PaymentMethodType: methodType,
 		Amount:            p.Amount,
+		StatusChangedAt:   selectedAt,
 	}
 
 	aggregate := PaymentIntentRequiresPaymentMethod{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
-		},
+		paymentIntentMeta: p.paymentIntentMeta.next(),
 		PaymentMethodType: methodType,
 		Amount:            p.Amount,
+		StatusChangedAt:   selectedAt,
 	}
 
 	return event, aggregate, nil
 }
 
 func (p PaymentIntentRequiresPaymentMethod) RequireConfirmation(method PaymentMethod, captureMethod PaymentCaptureMethod) (PaymentIntentEvent, PaymentIntent, error) {
-	contract.AssertValidatable(method)
-	contract.AssertValidatable(captureMethod)
+	if err := p.allow(PaymentIntentActionRequireConfirmation); err != nil {
+		return nil, nil, err
+	}
+
+	if err := contract.Validate(method, captureMethod); err != nil {
+		return nil, nil, err
+	}
 
 	if method.PaymentMethodType != p.PaymentMethodType {
-		return nil, nil, errors.New("payment method type is not allowed")
+		return nil, nil, invalidArgument("payment method type is not allowed")
 	}
 
 	seqNr := p.SeqNr + 1
@@ -137,20 +185,20 @@ func (p PaymentIntentRequiresPaymentMethod) RequireConfirmation(method PaymentMe
 	}
 
 	aggregate := PaymentIntentRequiresConfirmation{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
-		},
-		PaymentMethod: method,
-		CaptureMethod: captureMethod,
-		Amount:        p.Amount,
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     method,
+		CaptureMethod:     captureMethod,
+		Amount:            p.Amount,
 	}
 
 	return event, aggregate, nil
 }
 
-func (p PaymentIntentRequiresConfirmation) RequireAction() (PaymentIntentEvent, PaymentIntent, error) {
+func (p PaymentIntentRequiresConfirmation) RequireAction(requestedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionRequireAction); err != nil {
+		return nil, nil, err
+	}
+
 	contract.AssertValidatable(p.PaymentMethod)
 
 	seqNr := p.SeqNr + 1
@@ -160,29 +208,31 @@ func (p PaymentIntentRequiresConfirmation) RequireAction() (PaymentIntentEvent,
 			PaymentIntentID: p.ID,
 			SeqNr:           seqNr,
 		},
-		PaymentMethod: p.PaymentMethod,
-		Amount:        p.Amount,
+		PaymentMethod:   p.PaymentMethod,
+		Amount:          p.Amount,
+		StatusChangedAt: requestedAt,
 	}
 
 	aggregate := PaymentIntentRequiresAction{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
-		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            p.Amount,
+		StatusChangedAt:   requestedAt,
 	}
 
 	return event, aggregate, nil
 }
 
-func (p PaymentIntentRequiresConfirmation) RequireCapture() (PaymentIntentEvent, PaymentIntent, error) {
+func (p PaymentIntentRequiresConfirmation) RequireCapture(authorizedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionRequireCapture); err != nil {
+		return nil, nil, err
+	}
+
 	contract.AssertValidatable(p.PaymentMethod)
 
 	if p.CaptureMethod != PaymentCaptureMethodManual {
-		return nil, nil, errors.New("capture method must be manual to require capture after confirmation")
+		return nil, nil, invalidStateTransition("capture method must be manual to require capture after confirmation")
 	}
 
 	seqNr := p.SeqNr + 1
@@ -195,23 +245,25 @@ func (p PaymentIntentRequiresConfirmation) RequireCapture() (PaymentIntentEvent,
 		PaymentMethod: p.PaymentMethod,
 		CaptureMethod: p.CaptureMethod,
 		Amount:        p.Amount,
+		AuthorizedAt:  authorizedAt,
 	}
 
 	aggregate := PaymentIntentRequiresCapture{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
-		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            p.Amount,
+		AuthorizedAt:      authorizedAt,
 	}
 
 	return event, aggregate, nil
 }
 
 func (p PaymentIntentRequiresConfirmation) StartProcessing() (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionStartProcessing); err != nil {
+		return nil, nil, err
+	}
+
 	contract.AssertValidatable(p.PaymentMethod)
 
 	seqNr := p.SeqNr + 1
@@ -221,43 +273,144 @@ func (p PaymentIntentRequiresConfirmation) StartProcessing() (PaymentIntentEvent
 			PaymentIntentID: p.ID,
 			SeqNr:           seqNr,
 		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		PaymentMethod:  p.PaymentMethod,
+		CaptureMethod:  p.CaptureMethod,
+		Amount:         p.Amount,
+		CaptureAmount:  p.Amount,
+		AmountCaptured: p.Amount,
 	}
 
 	aggregate := PaymentIntentProcessing{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
-		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            p.Amount,
+		CaptureAmount:     p.Amount,
+		AmountCaptured:    p.Amount,
 	}
 
 	return event, aggregate, nil
 }
 
-func (p PaymentIntentRequiresConfirmation) ApplyConfirmationResult(next PaymentConfirmationNext) (PaymentIntentEvent, PaymentIntent, error) {
+func (p PaymentIntentRequiresConfirmation) ApplyConfirmationResult(next PaymentConfirmationNext, confirmedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	outcome := PaymentAttemptOutcomeSucceeded
+	if next == PaymentConfirmationNextRequiresAction {
+		outcome = PaymentAttemptOutcomeRequiresAction
+	}
+	attempt := newPaymentAttempt(p.PaymentMethod, outcome, confirmedAt)
+	p.Attempts = p.Attempts.Append(attempt)
+
+	var (
+		event     PaymentIntentEvent
+		aggregate PaymentIntent
+		err       error
+	)
 	switch next {
 	case PaymentConfirmationNextProcessing:
-		return p.StartProcessing()
+		event, aggregate, err = p.StartProcessing()
 	case PaymentConfirmationNextRequiresAction:
-		return p.RequireAction()
+		event, aggregate, err = p.RequireAction(confirmedAt)
 	case PaymentConfirmationNextRequiresCapture:
-		return p.RequireCapture()
+		event, aggregate, err = p.RequireCapture(confirmedAt)
 	default:
-		panic("invalid payment confirmation next")
+		return nil, nil, invalidArgument("unsupported payment confirmation next")
 	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return withPaymentAttempt(event, attempt), aggregate, nil
 }
 
-func (p PaymentIntentRequiresAction) RequireCapture() (PaymentIntentEvent, PaymentIntent, error) {
+// FailConfirmation records a failed attempt. A retryable decline sends the intent back to payment method selection
+// unless the attempt policy is exhausted, in which case it is canceled; other declines are terminal.
+func (p PaymentIntentRequiresConfirmation) FailConfirmation(
+	decline PaymentDecline,
+	failedAt time.Time,
+	policy PaymentAttemptPolicy,
+) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionFailConfirmation); err != nil {
+		return nil, nil, err
+	}
+
+	contract.AssertValidatable(p.PaymentMethod)
+
+	reason := decline.FailureReasonOr(PaymentFailureReasonConfirmationFailed)
+	return failPaymentAttempt(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, decline, decline.Retryable, failedAt, policy)
+}
+
+// failPaymentAttempt records the failed attempt and sends the intent back to payment method selection when retryable,
+// canceling it instead once the attempt policy is exhausted.
+func failPaymentAttempt(
+	meta paymentIntentMeta,
+	method PaymentMethod,
+	amount Money,
+	reason PaymentFailureReason,
+	decline PaymentDecline,
+	retryable bool,
+	failedAt time.Time,
+	policy PaymentAttemptPolicy,
+) (PaymentIntentEvent, PaymentIntent, error) {
+	attempt := failedPaymentAttempt(method, reason, decline, failedAt)
+	meta.Attempts = meta.Attempts.Append(attempt)
+
+	var (
+		event     PaymentIntentEvent
+		aggregate PaymentIntent
+		err       error
+	)
+	if retryable && policy.IsExhausted(meta.Attempts) {
+		event, aggregate, err = cancelPaymentIntent(meta, method, amount, PaymentCancellationReasonMaxAttemptsExceeded)
+	} else {
+		event, aggregate, err = failPaymentIntent(meta, method, amount, reason, retryable, failedAt)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return withPaymentAttempt(event, attempt), aggregate, nil
+}
+
+func failedPaymentAttempt(method PaymentMethod, reason PaymentFailureReason, decline PaymentDecline, failedAt time.Time) PaymentAttempt {
+	attempt := newPaymentAttempt(method, PaymentAttemptOutcomeFailed, failedAt)
+	attempt.FailureReason = reason
+	attempt.ProviderErrorCode = decline.Code
+	attempt.DeclineCategory = decline.Category
+	attempt.CustomerMessage = decline.CustomerMessage
+	return attempt
+}
+
+func withPaymentAttempt(event PaymentIntentEvent, attempt PaymentAttempt) PaymentIntentEvent {
+	switch e := event.(type) {
+	case PaymentIntentProcessingEvent:
+		e.Attempt = &attempt
+		return e
+	case PaymentIntentRequiresActionEvent:
+		e.Attempt = &attempt
+		return e
+	case PaymentIntentRequiresCaptureEvent:
+		e.Attempt = &attempt
+		return e
+	case PaymentIntentFailedEvent:
+		e.Attempt = &attempt
+		return e
+	case PaymentIntentCanceledEvent:
+		e.Attempt = &attempt
+		return e
+	default:
+		panic("event does not close a payment attempt")
+	}
+}
+
+func (p PaymentIntentRequiresAction) RequireCapture(authorizedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionRequireCapture); err != nil {
+		return nil, nil, err
+	}
+
 	contract.AssertValidatable(p.PaymentMethod)
 
 	if p.CaptureMethod != PaymentCaptureMethodManual {
-		return nil, nil, errors.New("capture method must be manual to require capture after action")
+		return nil, nil, invalidStateTransition("capture method must be manual to require capture after action")
 	}
 
 	seqNr := p.SeqNr + 1
@@ -270,27 +423,48 @@ func (p PaymentIntentRequiresAction) RequireCapture() (PaymentIntentEvent, Payme
 		PaymentMethod: p.PaymentMethod,
 		CaptureMethod: p.CaptureMethod,
 		Amount:        p.Amount,
+		AuthorizedAt:  authorizedAt,
 	}
 
 	aggregate := PaymentIntentRequiresCapture{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
-		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            p.Amount,
+		AuthorizedAt:      authorizedAt,
 	}
 
 	return event, aggregate, nil
 }
 
+// FailPayment records a payment the provider rejected while the customer was handling the next action, e.g. a failed
+// 3DS challenge. Nothing has been authorized yet, so the capture method does not matter: the intent goes back to payment
+// method selection unless the decline is terminal or the attempt policy is exhausted.
+func (p PaymentIntentRequiresAction) FailPayment(
+	decline PaymentDecline,
+	failedAt time.Time,
+	policy PaymentAttemptPolicy,
+) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionFailPayment); err != nil {
+		return nil, nil, err
+	}
+
+	contract.AssertValidatable(p.PaymentMethod)
+
+	reason := decline.FailureReasonOr(PaymentFailureReasonPaymentFailed)
+	retryable := decline.Category == "" || decline.Retryable
+	return failPaymentAttempt(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, decline, retryable, failedAt, policy)
+}
+
 func (p PaymentIntentRequiresAction) StartProcessing() (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionStartProcessing); err != nil {
+		return nil, nil, err
+	}
+
 	contract.AssertValidatable(p.PaymentMethod)
 
 	if p.CaptureMethod != PaymentCaptureMethodAutomatic {
-		return nil, nil, errors.New("capture method must be automatic to start processing after action")
+		return nil, nil, invalidStateTransition("capture method must be automatic to start processing after action")
 	}
 
 	seqNr := p.SeqNr + 1
@@ -300,30 +474,107 @@ func (p PaymentIntentRequiresAction) StartProcessing() (PaymentIntentEvent, Paym
 			PaymentIntentID: p.ID,
 			SeqNr:           seqNr,
 		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		PaymentMethod:  p.PaymentMethod,
+		CaptureMethod:  p.CaptureMethod,
+		Amount:         p.Amount,
+		CaptureAmount:  p.Amount,
+		AmountCaptured: p.Amount,
 	}
 
 	aggregate := PaymentIntentProcessing{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
-		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            p.Amount,
+		CaptureAmount:     p.Amount,
+		AmountCaptured:    p.Amount,
 	}
 
 	return event, aggregate, nil
 }
 
 func (p PaymentIntentRequiresCapture) StartProcessing() (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionStartProcessing); err != nil {
+		return nil, nil, err
+	}
+
+	return p.Capture(p.AmountCapturable(), PaymentOverCapturePolicy{})
+}
+
+func (p PaymentIntentRequiresCapture) AmountCapturable() Money {
+	return p.Amount - p.AmountCaptured
+}
+
+func (p PaymentIntentRequiresCapture) CaptureDeadline(policy PaymentCaptureDeadlinePolicy) time.Time {
+	return p.AuthorizedAt.Add(policy.WindowFor(p.PaymentMethod.PaymentMethodType))
+}
+
+func (p PaymentIntentRequiresCapture) IsAuthorizationExpired(policy PaymentCaptureDeadlinePolicy, now time.Time) bool {
+	return !now.Before(p.CaptureDeadline(policy))
+}
+
+// ExpireAuthorization closes an overdue authorization. Pieces that were already captured stay captured, so such
+// intents succeed with what has been captured instead of being canceled. The provider has already settled those
+// pieces and lets the rest of the authorization lapse, so no payment_succeeded will follow.
+func (p PaymentIntentRequiresCapture) ExpireAuthorization(policy PaymentCaptureDeadlinePolicy, now time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionExpireAuthorization); err != nil {
+		return nil, nil, err
+	}
+
+	if !p.IsAuthorizationExpired(policy, now) {
+		return nil, nil, invalidStateTransition("authorization has not expired yet")
+	}
+	if p.AmountCaptured > 0 {
+		contract.AssertValidatable(p.PaymentMethod)
+
+		event := PaymentIntentCompleteEvent{
+			paymentIntentEventMeta: paymentIntentEventMeta{
+				PaymentIntentID: p.ID,
+				SeqNr:           p.SeqNr + 1,
+			},
+			PaymentMethod:  p.PaymentMethod,
+			Amount:         p.Amount,
+			AmountCaptured: p.AmountCaptured,
+		}
+		aggregate := PaymentIntentSucceeded{
+			paymentIntentMeta: p.paymentIntentMeta.next(),
+			PaymentMethod:     p.PaymentMethod,
+			Amount:            p.Amount,
+			AmountCaptured:    p.AmountCaptured,
+		}
+		return event, aggregate, nil
+	}
+	return cancelPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, PaymentCancellationReasonAuthorizationExpired)
+}
+
+// Capture is the final capture: whatever has not been captured by this or earlier pieces is released.
+// amountToCapture may be zero only to close an authorization that already has captured pieces.
+func (p PaymentIntentRequiresCapture) Capture(amountToCapture Money, policy PaymentOverCapturePolicy) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionCapture); err != nil {
+		return nil, nil, err
+	}
+
 	contract.AssertValidatable(p.PaymentMethod)
 
 	if p.CaptureMethod != PaymentCaptureMethodManual {
-		return nil, nil, errors.New("capture method must be manual to start processing")
+		return nil, nil, invalidStateTransition("capture method must be manual to start processing")
+	}
+	if p.AmountCaptured == 0 {
+		if err := contract.Validate(amountToCapture); err != nil {
+			return nil, nil, err
+		}
+	}
+	totalCaptured, err := p.AmountCaptured.Add(amountToCapture)
+	if err != nil {
+		return nil, nil, err
+	}
+	if totalCaptured > policy.MaxCapturable(p.Amount) {
+		return nil, nil, invalidArgument("amount to capture exceeds capturable amount")
+	}
+
+	var amountReleased Money
+	if totalCaptured < p.Amount {
+		amountReleased = p.Amount - totalCaptured
 	}
 
 	seqNr := p.SeqNr + 1
@@ -333,26 +584,126 @@ func (p PaymentIntentRequiresCapture) StartProcessing() (PaymentIntentEvent, Pay
 			PaymentIntentID: p.ID,
 			SeqNr:           seqNr,
 		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		PaymentMethod:  p.PaymentMethod,
+		CaptureMethod:  p.CaptureMethod,
+		Amount:         p.Amount,
+		CaptureAmount:  amountToCapture,
+		AmountCaptured: totalCaptured,
+		AmountReleased: amountReleased,
 	}
 
 	aggregate := PaymentIntentProcessing{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            p.Amount,
+		CaptureAmount:     amountToCapture,
+		AmountCaptured:    totalCaptured,
+	}
+
+	return event, aggregate, nil
+}
+
+// CapturePartially captures one piece and keeps the authorization open for further pieces.
+// A piece that uses up the authorized amount is treated as the final capture.
+func (p PaymentIntentRequiresCapture) CapturePartially(amountToCapture Money) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionCapturePartially); err != nil {
+		return nil, nil, err
+	}
+
+	contract.AssertValidatable(p.PaymentMethod)
+
+	if p.CaptureMethod != PaymentCaptureMethodManual {
+		return nil, nil, invalidStateTransition("capture method must be manual to capture partially")
+	}
+	if err := contract.Validate(amountToCapture); err != nil {
+		return nil, nil, err
+	}
+	totalCaptured, err := p.AmountCaptured.Add(amountToCapture)
+	if err != nil {
+		return nil, nil, err
+	}
+	if totalCaptured > p.Amount {
+		return nil, nil, invalidArgument("amount to capture exceeds remaining authorized amount")
+	}
+	if totalCaptured == p.Amount {
+		return p.Capture(amountToCapture, PaymentOverCapturePolicy{})
+	}
+
+	seqNr := p.SeqNr + 1
+
+	event := PaymentIntentPartiallyCapturedEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: p.ID,
+			SeqNr:           seqNr,
 		},
-		PaymentMethod: p.PaymentMethod,
-		CaptureMethod: p.CaptureMethod,
-		Amount:        p.Amount,
+		PaymentMethod:  p.PaymentMethod,
+		Amount:         p.Amount,
+		CaptureAmount:  amountToCapture,
+		AmountCaptured: totalCaptured,
+	}
+
+	aggregate := PaymentIntentRequiresCapture{
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            p.Amount,
+		AmountCaptured:    totalCaptured,
+		AuthorizedAt:      p.AuthorizedAt,
+	}
+
+	return event, aggregate, nil
+}
+
+// IncrementAuthorization raises the authorized amount before capture; what was already captured is kept as is.
+func (p PaymentIntentRequiresCapture) IncrementAuthorization(incrementAmount Money) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionIncrementAuthorization); err != nil {
+		return nil, nil, err
+	}
+
+	contract.AssertValidatable(p.PaymentMethod)
+
+	if err := contract.Validate(incrementAmount); err != nil {
+		return nil, nil, err
+	}
+	amount, err := p.Amount.Add(incrementAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seqNr := p.SeqNr + 1
+
+	event := PaymentIntentAmountIncrementedEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: p.ID,
+			SeqNr:           seqNr,
+		},
+		PaymentMethod:   p.PaymentMethod,
+		PreviousAmount:  p.Amount,
+		IncrementAmount: incrementAmount,
+		Amount:          amount,
+	}
+
+	meta := p.paymentIntentMeta.next()
+	meta.Amount = amount
+
+	aggregate := PaymentIntentRequiresCapture{
+		paymentIntentMeta: meta,
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            amount,
+		AmountCaptured:    p.AmountCaptured,
+		AuthorizedAt:      p.AuthorizedAt,
 	}
 
 	return event, aggregate, nil
 }
 
 func (p PaymentIntentProcessing) Complete() (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionComplete); err != nil {
+		return nil, nil, err
+	}
+
 	contract.AssertValidatable(p.PaymentMethod)
 
 	seqNr := p.SeqNr + 1
@@ -362,37 +713,248 @@ func (p PaymentIntentProcessing) Complete() (PaymentIntentEvent, PaymentIntent,
 			PaymentIntentID: p.ID,
 			SeqNr:           seqNr,
 		},
-		PaymentMethod: p.PaymentMethod,
-		Amount:        p.Amount,
+		PaymentMethod:  p.PaymentMethod,
+		Amount:         p.Amount,
+		AmountCaptured: p.AmountCaptured,
 	}
 
 	aggregate := PaymentIntentSucceeded{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     p.ID,
-			SeqNr:  seqNr,
-			Amount: p.Amount,
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		Amount:            p.Amount,
+		AmountCaptured:    p.AmountCaptured,
+	}
+
+	return event, aggregate, nil
+}
+
+// FailPayment records a payment the provider rejected after processing started, e.g. a bank transfer or wallet payment
+// that fails asynchronously. A manually captured payment has used up its authorization, so the intent is canceled, or
+// succeeds with the pieces earlier partial captures settled. An automatically captured one goes back to payment method
+// selection when the decline is retryable and the attempt policy allows another attempt; failures the provider did not
+// classify count as retryable.
+func (p PaymentIntentProcessing) FailPayment(
+	decline PaymentDecline,
+	failedAt time.Time,
+	policy PaymentAttemptPolicy,
+) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionFailPayment); err != nil {
+		return nil, nil, err
+	}
+
+	contract.AssertValidatable(p.PaymentMethod)
+
+	if p.AmountSettled() > 0 {
+		event, aggregate := p.succeedWithSettledPieces()
+		return event, aggregate, nil
+	}
+	reason := decline.FailureReasonOr(PaymentFailureReasonPaymentFailed)
+	retryable := p.CaptureMethod == PaymentCaptureMethodAutomatic && (decline.Category == "" || decline.Retryable)
+	return failPaymentAttempt(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, decline, retryable, failedAt, policy)
+}
+
+func (p PaymentIntentSucceeded) AmountRefundable() Money {
+	return p.AmountCaptured - p.Refunds.AmountReserved()
+}
+
+func (p PaymentIntentSucceeded) RequestRefund(refundID PaymentRefundID, amount Money) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionRequestRefund); err != nil {
+		return nil, nil, err
+	}
+
+	if err := contract.Validate(refundID, amount); err != nil {
+		return nil, nil, err
+	}
+
+	if _, _, exists := p.Refunds.Find(refundID); exists {
+		return nil, nil, fmt.Errorf("%w: refund already exists", ErrConflict)
+	}
+	if amount > p.AmountRefundable() {
+		return nil, nil, invalidArgument("refund amount exceeds refundable amount")
+	}
+
+	seqNr := p.SeqNr + 1
+
+	event := PaymentIntentRefundRequestedEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: p.ID,
+			SeqNr:           seqNr,
 		},
-		PaymentMethod: p.PaymentMethod,
-		Amount:        p.Amount,
+		RefundID: refundID,
+		Amount:   amount,
+	}
+
+	aggregate := PaymentIntentSucceeded{
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		Amount:            p.Amount,
+		AmountCaptured:    p.AmountCaptured,
+		Refunds: append(slices.Clone(p.Refunds), PaymentRefund{
+			ID:     refundID,
+			Amount: amount,
+			Status: PaymentRefundStatusPending,
+		}),
+	}
+
+	return event, aggregate, nil
+}
+
+func (p PaymentIntentSucceeded) SucceedRefund(refundID PaymentRefundID) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionSucceedRefund); err != nil {
+		return nil, nil, err
+	}
+
+	refund, idx, err := p.pendingRefund(refundID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seqNr := p.SeqNr + 1
+
+	event := PaymentIntentRefundSucceededEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: p.ID,
+			SeqNr:           seqNr,
+		},
+		RefundID: refundID,
+		Amount:   refund.Amount,
+	}
+
+	refunds := slices.Clone(p.Refunds)
+	refunds[idx].Status = PaymentRefundStatusSucceeded
+
+	aggregate := PaymentIntentSucceeded{
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		Amount:            p.Amount,
+		AmountCaptured:    p.AmountCaptured,
+		Refunds:           refunds,
 	}
 
 	return event, aggregate, nil
 }
 
-func (p PaymentIntentRequiresConfirmation) Fail(reason PaymentFailureReason, retryable bool) (PaymentIntentEvent, PaymentIntent, error) {
-	return failPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, retryable)
+func (p PaymentIntentSucceeded) FailRefund(refundID PaymentRefundID, reason PaymentFailureReason) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionFailRefund); err != nil {
+		return nil, nil, err
+	}
+
+	if err := contract.Validate(reason); err != nil {
+		return nil, nil, err
+	}
+
+	refund, idx, err := p.pendingRefund(refundID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seqNr := p.SeqNr + 1
+
+	event := PaymentIntentRefundFailedEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: p.ID,
+			SeqNr:           seqNr,
+		},
+		RefundID: refundID,
+		Amount:   refund.Amount,
+		Reason:   reason,
+	}
+
+	refunds := slices.Clone(p.Refunds)
+	refunds[idx].Status = PaymentRefundStatusFailed
+	refunds[idx].FailureReason = reason
+
+	aggregate := PaymentIntentSucceeded{
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		Amount:            p.Amount,
+		AmountCaptured:    p.AmountCaptured,
+		Refunds:           refunds,
+	}
+
+	return event, aggregate, nil
 }
 
-func (p PaymentIntentRequiresAction) Fail(reason PaymentFailureReason, retryable bool) (PaymentIntentEvent, PaymentIntent, error) {
-	return failPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, retryable)
+func (p PaymentIntentSucceeded) pendingRefund(refundID PaymentRefundID) (PaymentRefund, int, error) {
+	if err := contract.Validate(refundID); err != nil {
+		return PaymentRefund{}, -1, err
+	}
+
+	refund, idx, exists := p.Refunds.Find(refundID)
+	if !exists {
+		return PaymentRefund{}, -1, NotFoundError{Resource: "refund", ID: string(refundID)}
+	}
+	if refund.Status != PaymentRefundStatusPending {
+		return PaymentRefund{}, -1, invalidStateTransition("refund is not pending")
+	}
+	return refund, idx, nil
 }
 
-func (p PaymentIntentRequiresCapture) Fail(reason PaymentFailureReason, retryable bool) (PaymentIntentEvent, PaymentIntent, error) {
-	return failPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, retryable)
+func (p PaymentIntentRequiresConfirmation) Fail(reason PaymentFailureReason, retryable bool, failedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionFail); err != nil {
+		return nil, nil, err
+	}
+
+	return failPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, retryable, failedAt)
 }
 
-func (p PaymentIntentProcessing) Fail(reason PaymentFailureReason, retryable bool) (PaymentIntentEvent, PaymentIntent, error) {
-	return failPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, retryable)
+func (p PaymentIntentRequiresAction) Fail(reason PaymentFailureReason, retryable bool, failedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionFail); err != nil {
+		return nil, nil, err
+	}
+
+	return failPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, retryable, failedAt)
+}
+
+func (p PaymentIntentRequiresCapture) Fail(reason PaymentFailureReason, retryable bool, failedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionFail); err != nil {
+		return nil, nil, err
+	}
+
+	return failPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, retryable, failedAt)
+}
+
+// Fail gives up on the capture in flight. Pieces that earlier partial captures settled stay captured, so such intents
+// succeed with those pieces, as ExpireAuthorization does, instead of being canceled.
+func (p PaymentIntentProcessing) Fail(reason PaymentFailureReason, retryable bool, failedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionFail); err != nil {
+		return nil, nil, err
+	}
+
+	if p.AmountSettled() > 0 {
+		if err := contract.Validate(reason); err != nil {
+			return nil, nil, err
+		}
+		event, aggregate := p.succeedWithSettledPieces()
+		return event, aggregate, nil
+	}
+	return failPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason, retryable, failedAt)
+}
+
+// AmountSettled is what earlier partial captures settled before the capture in flight.
+func (p PaymentIntentProcessing) AmountSettled() Money {
+	return p.AmountCaptured - p.CaptureAmount
+}
+
+func (p PaymentIntentProcessing) succeedWithSettledPieces() (PaymentIntentEvent, PaymentIntent) {
+	contract.AssertValidatable(p.PaymentMethod)
+
+	event := PaymentIntentCompleteEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: p.ID,
+			SeqNr:           p.SeqNr + 1,
+		},
+		PaymentMethod:  p.PaymentMethod,
+		Amount:         p.Amount,
+		AmountCaptured: p.AmountSettled(),
+	}
+	aggregate := PaymentIntentSucceeded{
+		paymentIntentMeta: p.paymentIntentMeta.next(),
+		PaymentMethod:     p.PaymentMethod,
+		Amount:            p.Amount,
+		AmountCaptured:    p.AmountSettled(),
+	}
+	return event, aggregate
 }
 
 func failPaymentIntent(
@@ -401,9 +963,12 @@ func failPaymentIntent(
 	amount Money,
 	reason PaymentFailureReason,
 	retryable bool,
+	failedAt time.Time,
 ) (PaymentIntentEvent, PaymentIntent, error) {
 	contract.AssertValidatable(paymentMethod)
-	contract.AssertValidatable(reason)
+	if err := contract.Validate(reason); err != nil {
+		return nil, nil, err
+	}
 
 	seqNr := meta.SeqNr + 1
 
@@ -417,17 +982,15 @@ func failPaymentIntent(
 			PaymentMethod:     paymentMethod,
 			Amount:            amount,
 			Reason:            reason,
+			StatusChangedAt:   failedAt,
 		}
 
 		aggregate := PaymentIntentRequiresPaymentMethod{
-			paymentIntentMeta: paymentIntentMeta{
-				ID:     meta.ID,
-				SeqNr:  seqNr,
-				Amount: amount,
-			},
+			paymentIntentMeta: meta.next(),
 			PaymentMethodType: paymentMethod.PaymentMethodType,
 			Amount:            amount,
 			FailureReason:     reason,
+			StatusChangedAt:   failedAt,
 		}
 
 		return event, aggregate, nil
@@ -444,14 +1007,210 @@ func failPaymentIntent(
 	}
 
 	aggregate := PaymentIntentCanceled{
-		paymentIntentMeta: paymentIntentMeta{
-			ID:     meta.ID,
-			SeqNr:  seqNr,
-			Amount: amount,
+		paymentIntentMeta: meta.next(),
+		PaymentMethod:     paymentMethod,
+		Amount:            amount,
+		FailureReason:     reason,
+	}
+
+	return event, aggregate, nil
+}
+
+// ReselectPaymentMethodType lets the customer pick another payment method type after a retryable failure.
+func (p PaymentIntentRequiresPaymentMethod) ReselectPaymentMethodType(types PaymentMethodTypes, reselectedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionReselectPaymentMethodType); err != nil {
+		return nil, nil, err
+	}
+
+	if err := contract.Validate(types); err != nil {
+		return nil, nil, err
+	}
+
+	if len(p.FailureReason) == 0 {
+		return nil, nil, invalidStateTransition("payment method type can only be reselected after a failure")
+	}
+
+	seqNr := p.SeqNr + 1
+
+	event := PaymentIntentPaymentMethodTypeReselectedEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: p.ID,
+			SeqNr:           seqNr,
 		},
-		PaymentMethod: paymentMethod,
-		Amount:        amount,
-		FailureReason: reason,
+		PreviousPaymentMethodType: p.PaymentMethodType,
+		PaymentMethodTypes:        types,
+		Amount:                    p.Amount,
+		StatusChangedAt:           reselectedAt,
+	}
+
+	aggregate := PaymentIntentRequiresPaymentMethodType{
+		paymentIntentMeta:  p.paymentIntentMeta.next(),
+		PaymentMethodTypes: types,
+		Amount:             p.Amount,
+		StatusChangedAt:    reselectedAt,
+	}
+
+	return event, aggregate, nil
+}
+
+func (p PaymentIntentRequiresPaymentMethodType) UpdateAmount(cart Cart) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionUpdateAmount); err != nil {
+		return nil, nil, err
+	}
+
+	event, meta, err := updatePaymentIntentAmount(p.paymentIntentMeta, p.Amount, cart)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, PaymentIntentRequiresPaymentMethodType{
+		paymentIntentMeta:  meta,
+		PaymentMethodTypes: p.PaymentMethodTypes,
+		Amount:             event.Amount,
+		StatusChangedAt:    p.StatusChangedAt,
+	}, nil
+}
+
+func (p PaymentIntentRequiresPaymentMethod) UpdateAmount(cart Cart) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionUpdateAmount); err != nil {
+		return nil, nil, err
+	}
+
+	event, meta, err := updatePaymentIntentAmount(p.paymentIntentMeta, p.Amount, cart)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, PaymentIntentRequiresPaymentMethod{
+		paymentIntentMeta: meta,
+		PaymentMethodType: p.PaymentMethodType,
+		Amount:            event.Amount,
+		FailureReason:     p.FailureReason,
+		StatusChangedAt:   p.StatusChangedAt,
+	}, nil
+}
+
+func (p PaymentIntentRequiresConfirmation) UpdateAmount(cart Cart) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionUpdateAmount); err != nil {
+		return nil, nil, err
+	}
+
+	event, meta, err := updatePaymentIntentAmount(p.paymentIntentMeta, p.Amount, cart)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, PaymentIntentRequiresConfirmation{
+		paymentIntentMeta: meta,
+		PaymentMethod:     p.PaymentMethod,
+		CaptureMethod:     p.CaptureMethod,
+		Amount:            event.Amount,
+	}, nil
+}
+
+// updatePaymentIntentAmount replaces the cart snapshot; the cart must be the one the intent was created for.
+func updatePaymentIntentAmount(
+	meta paymentIntentMeta,
+	previousAmount Money,
+	cart Cart,
+) (PaymentIntentAmountChangedEvent, paymentIntentMeta, error) {
+	if err := contract.Validate(cart); err != nil {
+		return PaymentIntentAmountChangedEvent{}, paymentIntentMeta{}, err
+	}
+
+	if cart.BusinessID != meta.BusinessID || cart.CartID != meta.CartID {
+		return PaymentIntentAmountChangedEvent{}, paymentIntentMeta{}, invalidArgument("cart does not belong to payment intent")
+	}
+
+	amount := cart.CalculateAmount()
+	if err := contract.Validate(amount); err != nil {
+		return PaymentIntentAmountChangedEvent{}, paymentIntentMeta{}, err
+	}
+
+	next := meta.next()
+	next.Amount = amount
+	next.CartItems = cart.Items
+
+	event := PaymentIntentAmountChangedEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: meta.ID,
+			SeqNr:           next.SeqNr,
+		},
+		CartItems:      cart.Items,
+		PreviousAmount: previousAmount,
+		Amount:         amount,
+	}
+
+	return event, next, nil
+}
+
+func (p PaymentIntentRequiresPaymentMethodType) Cancel(reason PaymentCancellationReason) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionCancel); err != nil {
+		return nil, nil, err
+	}
+
+	return cancelPaymentIntent(p.paymentIntentMeta, PaymentMethod{}, p.Amount, reason)
+}
+
+func (p PaymentIntentRequiresPaymentMethod) Cancel(reason PaymentCancellationReason) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionCancel); err != nil {
+		return nil, nil, err
+	}
+
+	return cancelPaymentIntent(p.paymentIntentMeta, PaymentMethod{}, p.Amount, reason)
+}
+
+func (p PaymentIntentRequiresConfirmation) Cancel(reason PaymentCancellationReason) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionCancel); err != nil {
+		return nil, nil, err
+	}
+
+	return cancelPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason)
+}
+
+func (p PaymentIntentRequiresAction) Cancel(reason PaymentCancellationReason) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionCancel); err != nil {
+		return nil, nil, err
+	}
+
+	return cancelPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason)
+}
+
+func (p PaymentIntentRequiresCapture) Cancel(reason PaymentCancellationReason) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := p.allow(PaymentIntentActionCancel); err != nil {
+		return nil, nil, err
+	}
+
+	if p.AmountCaptured > 0 {
+		return nil, nil, invalidStateTransition("payment intent with captured pieces must be closed by final capture")
+	}
+	return cancelPaymentIntent(p.paymentIntentMeta, p.PaymentMethod, p.Amount, reason)
+}
+
+func cancelPaymentIntent(
+	meta paymentIntentMeta,
+	paymentMethod PaymentMethod,
+	amount Money,
+	reason PaymentCancellationReason,
+) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := contract.Validate(reason); err != nil {
+		return nil, nil, err
+	}
+
+	seqNr := meta.SeqNr + 1
+
+	event := PaymentIntentCanceledEvent{
+		paymentIntentEventMeta: paymentIntentEventMeta{
+			PaymentIntentID: meta.ID,
+			SeqNr:           seqNr,
+		},
+		PaymentMethod:      paymentMethod,
+		Amount:             amount,
+		CancellationReason: reason,
+	}
+
+	aggregate := PaymentIntentCanceled{
+		paymentIntentMeta:  meta.next(),
+		PaymentMethod:      paymentMethod,
+		Amount:             amount,
+		CancellationReason: reason,
 	}
 
 	return event, aggregate, nil
@@ -0,0 +1,92 @@
+package domain
+
+import "slices"
+
+// PaymentIntentTransition is one edge of the PaymentIntent state machine. Actions that can end in
+// different states depending on their arguments list every possible target.
+type PaymentIntentTransition struct {
+	From   PaymentIntentStatus
+	Action PaymentIntentAction
+	To     []PaymentIntentStatus
+}
+
+const (
+	PaymentIntentActionReselectPaymentMethodType PaymentIntentAction = "reselect_payment_method_type"
+	PaymentIntentActionUpdateAmount              PaymentIntentAction = "update_amount"
+	PaymentIntentActionFailConfirmation          PaymentIntentAction = "fail_confirmation"
+	PaymentIntentActionFailPayment               PaymentIntentAction = "fail_payment"
+	PaymentIntentActionCapture                   PaymentIntentAction = "capture"
+	PaymentIntentActionCapturePartially          PaymentIntentAction = "capture_partially"
+	PaymentIntentActionIncrementAuthorization    PaymentIntentAction = "increment_authorization"
+	PaymentIntentActionExpireAuthorization       PaymentIntentAction = "expire_authorization"
+	PaymentIntentActionRequestRefund             PaymentIntentAction = "request_refund"
+	PaymentIntentActionSucceedRefund             PaymentIntentAction = "succeed_refund"
+	PaymentIntentActionFailRefund                PaymentIntentAction = "fail_refund"
+)
+
+var paymentIntentTransitions = []PaymentIntentTransition{
+	{From: PaymentIntentStatusRequiresPaymentMethodType, Action: PaymentIntentActionRequirePaymentMethod, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod}},
+	{From: PaymentIntentStatusRequiresPaymentMethodType, Action: PaymentIntentActionUpdateAmount, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethodType}},
+	{From: PaymentIntentStatusRequiresPaymentMethodType, Action: PaymentIntentActionCancel, To: []PaymentIntentStatus{PaymentIntentStatusCanceled}},
+
+	{From: PaymentIntentStatusRequiresPaymentMethod, Action: PaymentIntentActionRequireConfirmation, To: []PaymentIntentStatus{PaymentIntentStatusRequiresConfirmation}},
+	{From: PaymentIntentStatusRequiresPaymentMethod, Action: PaymentIntentActionReselectPaymentMethodType, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethodType}},
+	{From: PaymentIntentStatusRequiresPaymentMethod, Action: PaymentIntentActionUpdateAmount, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod}},
+	{From: PaymentIntentStatusRequiresPaymentMethod, Action: PaymentIntentActionCancel, To: []PaymentIntentStatus{PaymentIntentStatusCanceled}},
+
+	{From: PaymentIntentStatusRequiresConfirmation, Action: PaymentIntentActionRequireAction, To: []PaymentIntentStatus{PaymentIntentStatusRequiresAction}},
+	{From: PaymentIntentStatusRequiresConfirmation, Action: PaymentIntentActionRequireCapture, To: []PaymentIntentStatus{PaymentIntentStatusRequiresCapture}},
+	{From: PaymentIntentStatusRequiresConfirmation, Action: PaymentIntentActionStartProcessing, To: []PaymentIntentStatus{PaymentIntentStatusProcessing}},
+	{From: PaymentIntentStatusRequiresConfirmation, Action: PaymentIntentActionUpdateAmount, To: []PaymentIntentStatus{PaymentIntentStatusRequiresConfirmation}},
+	{From: PaymentIntentStatusRequiresConfirmation, Action: PaymentIntentActionFail, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod, PaymentIntentStatusCanceled}},
+	{From: PaymentIntentStatusRequiresConfirmation, Action: PaymentIntentActionFailConfirmation, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod, PaymentIntentStatusCanceled}},
+	{From: PaymentIntentStatusRequiresConfirmation, Action: PaymentIntentActionCancel, To: []PaymentIntentStatus{PaymentIntentStatusCanceled}},
+
+	{From: PaymentIntentStatusRequiresAction, Action: PaymentIntentActionRequireCapture, To: []PaymentIntentStatus{PaymentIntentStatusRequiresCapture}},
+	{From: PaymentIntentStatusRequiresAction, Action: PaymentIntentActionStartProcessing, To: []PaymentIntentStatus{PaymentIntentStatusProcessing}},
+	{From: PaymentIntentStatusRequiresAction, Action: PaymentIntentActionFail, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod, PaymentIntentStatusCanceled}},
+	{From: PaymentIntentStatusRequiresAction, Action: PaymentIntentActionFailPayment, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod, PaymentIntentStatusCanceled}},
+	{From: PaymentIntentStatusRequiresAction, Action: PaymentIntentActionCancel, To: []PaymentIntentStatus{PaymentIntentStatusCanceled}},
+
+	{From: PaymentIntentStatusRequiresCapture, Action: PaymentIntentActionStartProcessing, To: []PaymentIntentStatus{PaymentIntentStatusProcessing}},
+	{From: PaymentIntentStatusRequiresCapture, Action: PaymentIntentActionCapture, To: []PaymentIntentStatus{PaymentIntentStatusProcessing}},
+	{From: PaymentIntentStatusRequiresCapture, Action: PaymentIntentActionCapturePartially, To: []PaymentIntentStatus{PaymentIntentStatusRequiresCapture, PaymentIntentStatusProcessing}},
+	{From: PaymentIntentStatusRequiresCapture, Action: PaymentIntentActionIncrementAuthorization, To: []PaymentIntentStatus{PaymentIntentStatusRequiresCapture}},
+	{From: PaymentIntentStatusRequiresCapture, Action: PaymentIntentActionExpireAuthorization, To: []PaymentIntentStatus{PaymentIntentStatusSucceeded, PaymentIntentStatusCanceled}},
+	{From: PaymentIntentStatusRequiresCapture, Action: PaymentIntentActionFail, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod, PaymentIntentStatusCanceled}},
+	{From: PaymentIntentStatusRequiresCapture, Action: PaymentIntentActionCancel, To: []PaymentIntentStatus{PaymentIntentStatusCanceled}},
+
+	{From: PaymentIntentStatusProcessing, Action: PaymentIntentActionComplete, To: []PaymentIntentStatus{PaymentIntentStatusSucceeded}},
+	{From: PaymentIntentStatusProcessing, Action: PaymentIntentActionFail, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod, PaymentIntentStatusSucceeded, PaymentIntentStatusCanceled}},
+	{From: PaymentIntentStatusProcessing, Action: PaymentIntentActionFailPayment, To: []PaymentIntentStatus{PaymentIntentStatusRequiresPaymentMethod, PaymentIntentStatusSucceeded, PaymentIntentStatusCanceled}},
+
+	{From: PaymentIntentStatusSucceeded, Action: PaymentIntentActionRequestRefund, To: []PaymentIntentStatus{PaymentIntentStatusSucceeded}},
+	{From: PaymentIntentStatusSucceeded, Action: PaymentIntentActionSucceedRefund, To: []PaymentIntentStatus{PaymentIntentStatusSucceeded}},
+	{From: PaymentIntentStatusSucceeded, Action: PaymentIntentActionFailRefund, To: []PaymentIntentStatus{PaymentIntentStatusSucceeded}},
+}
+
+func PaymentIntentTransitions() []PaymentIntentTransition {
+	transitions := make([]PaymentIntentTransition, len(paymentIntentTransitions))
+	for idx, transition := range paymentIntentTransitions {
+		transition.To = slices.Clone(transition.To)
+		transitions[idx] = transition
+	}
+	return transitions
+}
+
+func FindPaymentIntentTransition(from PaymentIntentStatus, action PaymentIntentAction) (PaymentIntentTransition, bool) {
+	for _, transition := range paymentIntentTransitions {
+		if transition.From == from && transition.Action == action {
+			transition.To = slices.Clone(transition.To)
+			return transition, true
+		}
+	}
+	return PaymentIntentTransition{}, false
+}
+
+func ValidatePaymentIntentTransition(from PaymentIntentStatus, action PaymentIntentAction) error {
+	if _, ok := FindPaymentIntentTransition(from, action); !ok {
+		return ErrInvalidTransition{From: from, Action: action}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "errors"
+
+type PaymentIntentStatus string
+
+const (
+	PaymentIntentStatusRequiresPaymentMethodType PaymentIntentStatus = "requires_payment_method_type"
+	PaymentIntentStatusRequiresPaymentMethod     PaymentIntentStatus = "requires_payment_method"
+	PaymentIntentStatusRequiresConfirmation      PaymentIntentStatus = "requires_confirmation"
+	PaymentIntentStatusRequiresAction            PaymentIntentStatus = "requires_action"
+	PaymentIntentStatusRequiresCapture           PaymentIntentStatus = "requires_capture"
+	PaymentIntentStatusProcessing                PaymentIntentStatus = "processing"
+	PaymentIntentStatusSucceeded                 PaymentIntentStatus = "succeeded"
+	PaymentIntentStatusCanceled                  PaymentIntentStatus = "canceled"
+)
+
+func (p PaymentIntentStatus) Validate() error {
+	if len(p) == 0 {
+		return errors.New("payment intent status is empty")
+	}
+
+	switch p {
+	case PaymentIntentStatusRequiresPaymentMethodType,
+		PaymentIntentStatusRequiresPaymentMethod,
+		PaymentIntentStatusRequiresConfirmation,
+		PaymentIntentStatusRequiresAction,
+		PaymentIntentStatusRequiresCapture,
+		PaymentIntentStatusProcessing,
+		PaymentIntentStatusSucceeded,
+		PaymentIntentStatusCanceled:
+		return nil
+	default:
+		return errors.New("unsupported payment intent status")
+	}
+}
+
+func (p PaymentIntentStatus) IsTerminal() bool {
+	return p == PaymentIntentStatusSucceeded || p == PaymentIntentStatusCanceled
+}
+
+func ActivePaymentIntentStatuses() []PaymentIntentStatus {
+	return []PaymentIntentStatus{
+		PaymentIntentStatusRequiresPaymentMethodType,
+		PaymentIntentStatusRequiresPaymentMethod,
+		PaymentIntentStatusRequiresConfirmation,
+		PaymentIntentStatusRequiresAction,
+		PaymentIntentStatusRequiresCapture,
+		PaymentIntentStatusProcessing,
+	}
+}
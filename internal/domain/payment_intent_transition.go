@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	PaymentIntentAction string
+
+	// ErrInvalidTransition is returned when an action is not supported in the intent's current state.
+	// errors.Is(err, ErrInvalidStateTransition) holds for it.
+	ErrInvalidTransition struct {
+		From   PaymentIntentStatus
+		Action PaymentIntentAction
+	}
+)
+
+const (
+	PaymentIntentActionRequirePaymentMethod PaymentIntentAction = "require_payment_method"
+	PaymentIntentActionRequireConfirmation  PaymentIntentAction = "require_confirmation"
+	PaymentIntentActionRequireAction        PaymentIntentAction = "require_action"
+	PaymentIntentActionRequireCapture       PaymentIntentAction = "require_capture"
+	PaymentIntentActionStartProcessing      PaymentIntentAction = "start_processing"
+	PaymentIntentActionComplete             PaymentIntentAction = "complete"
+	PaymentIntentActionFail                 PaymentIntentAction = "fail"
+	PaymentIntentActionCancel               PaymentIntentAction = "cancel"
+)
+
+func (e ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("invalid transition: cannot %s from %s", e.Action, e.From)
+}
+
+func (e ErrInvalidTransition) Is(target error) bool {
+	return target == ErrInvalidStateTransition
+}
+
+type (
+	paymentIntentStatusMarker interface {
+		status() PaymentIntentStatus
+	}
+
+	requiresPaymentMethodTypeStatus struct{}
+	requiresPaymentMethodStatus     struct{}
+	requiresConfirmationStatus      struct{}
+	requiresActionStatus            struct{}
+	requiresCaptureStatus           struct{}
+	processingStatus                struct{}
+	succeededStatus                 struct{}
+	canceledStatus                  struct{}
+
+	// unsupportedTransitions is embedded by every state and rejects all actions;
+	// states override the methods for the transitions the table lists for them.
+	unsupportedTransitions[S paymentIntentStatusMarker] struct{}
+)
+
+func (requiresPaymentMethodTypeStatus) status() PaymentIntentStatus {
+	return PaymentIntentStatusRequiresPaymentMethodType
+}
+
+func (requiresPaymentMethodStatus) status() PaymentIntentStatus {
+	return PaymentIntentStatusRequiresPaymentMethod
+}
+
+func (requiresConfirmationStatus) status() PaymentIntentStatus {
+	return PaymentIntentStatusRequiresConfirmation
+}
+
+func (requiresActionStatus) status() PaymentIntentStatus {
+	return PaymentIntentStatusRequiresAction
+}
+
+func (requiresCaptureStatus) status() PaymentIntentStatus {
+	return PaymentIntentStatusRequiresCapture
+}
+
+func (processingStatus) status() PaymentIntentStatus {
+	return PaymentIntentStatusProcessing
+}
+
+func (succeededStatus) status() PaymentIntentStatus {
+	return PaymentIntentStatusSucceeded
+}
+
+func (canceledStatus) status() PaymentIntentStatus {
+	return PaymentIntentStatusCanceled
+}
+
+func (u unsupportedTransitions[S]) Status() PaymentIntentStatus {
+	var s S
+	return s.status()
+}
+
+// allow consults the transition table, which decides whether the state accepts the action. Every transition method
+// calls it before looking at its arguments.
+func (u unsupportedTransitions[S]) allow(action PaymentIntentAction) error {
+	return ValidatePaymentIntentTransition(u.Status(), action)
+}
+
+func (u unsupportedTransitions[S]) reject(action PaymentIntentAction) (PaymentIntentEvent, PaymentIntent, error) {
+	if err := u.allow(action); err != nil {
+		return nil, nil, err
+	}
+	panic(fmt.Sprintf("transition table lists %s from %s but the state does not implement it", action, u.Status()))
+}
+
+func (u unsupportedTransitions[S]) RequirePaymentMethod(methodType PaymentMethodType, selectedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	return u.reject(PaymentIntentActionRequirePaymentMethod)
+}
+
+func (u unsupportedTransitions[S]) RequireConfirmation(method PaymentMethod, captureMethod PaymentCaptureMethod) (PaymentIntentEvent, PaymentIntent, error) {
+	return u.reject(PaymentIntentActionRequireConfirmation)
+}
+
+func (u unsupportedTransitions[S]) RequireAction(requestedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	return u.reject(PaymentIntentActionRequireAction)
+}
+
+func (u unsupportedTransitions[S]) RequireCapture(authorizedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	return u.reject(PaymentIntentActionRequireCapture)
+}
+
+func (u unsupportedTransitions[S]) StartProcessing() (PaymentIntentEvent, PaymentIntent, error) {
+	return u.reject(PaymentIntentActionStartProcessing)
+}
+
+func (u unsupportedTransitions[S]) Complete() (PaymentIntentEvent, PaymentIntent, error) {
+	return u.reject(PaymentIntentActionComplete)
+}
+
+func (u unsupportedTransitions[S]) Fail(reason PaymentFailureReason, retryable bool, failedAt time.Time) (PaymentIntentEvent, PaymentIntent, error) {
+	return u.reject(PaymentIntentActionFail)
+}
+
+func (u unsupportedTransitions[S]) Cancel(reason PaymentCancellationReason) (PaymentIntentEvent, PaymentIntent, error) {
+	return u.reject(PaymentIntentActionCancel)
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentIntent_TransitionMatrix(t *testing.T) {
+	states := paymentIntentStatesForTest(t)
+
+	actions := map[PaymentIntentAction]func(PaymentIntent) (PaymentIntent, error){
+		PaymentIntentActionRequirePaymentMethod: func(p PaymentIntent) (PaymentIntent, error) {
+			_, next, err := p.RequirePaymentMethod(PaymentMethodTypeCard, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			return next, err
+		},
+		PaymentIntentActionRequireConfirmation: func(p PaymentIntent) (PaymentIntent, error) {
+			_, next, err := p.RequireConfirmation(cardPaymentMethodForTest(), PaymentCaptureMethodManual)
+			return next, err
+		},
+		PaymentIntentActionRequireAction: func(p PaymentIntent) (PaymentIntent, error) {
+			_, next, err := p.RequireAction(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			return next, err
+		},
+		PaymentIntentActionRequireCapture: func(p PaymentIntent) (PaymentIntent, error) {
+			_, next, err := p.RequireCapture(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			return next, err
+		},
+		PaymentIntentActionStartProcessing: func(p PaymentIntent) (PaymentIntent, error) {
+			_, next, err := p.StartProcessing()
+			return next, err
+		},
+		PaymentIntentActionComplete: func(p PaymentIntent) (PaymentIntent, error) {
+			_, next, err := p.Complete()
+			return next, err
+		},
+		PaymentIntentActionFail: func(p PaymentIntent) (PaymentIntent, error) {
+			_, next, err := p.Fail(PaymentFailureReasonProcessingError, true, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			return next, err
+		},
+		PaymentIntentActionCancel: func(p PaymentIntent) (PaymentIntent, error) {
+			_, next, err := p.Cancel(PaymentCancellationReasonRequestedByCustomer)
+			return next, err
+		},
+	}
+
+	require.Len(t, states, 8)
+	require.Len(t, actions, 8)
+
+	for status, state := range states {
+		require.Equal(t, status, state.Status())
+
+		for action, apply := range actions {
+			t.Run(string(status)+"/"+string(action), func(t *testing.T) {
+				next, err := apply(state)
+
+				var invalid ErrInvalidTransition
+				isInvalid := errors.As(err, &invalid)
+				// 状態ごとに受け付けるアクションは宣言的な遷移表が正とする
+				if transition, ok := FindPaymentIntentTransition(status, action); ok {
+					assert.False(t, isInvalid, "expected %s to be allowed from %s, got %v", action, status, err)
+					if err == nil {
+						assert.Contains(t, transition.To, next.Status())
+					}
+					return
+				}
+
+				require.True(t, isInvalid, "expected ErrInvalidTransition, got %v", err)
+				assert.Equal(t, ErrInvalidTransition{From: status, Action: action}, invalid)
+				assert.ErrorIs(t, err, ErrInvalidStateTransition)
+			})
+		}
+	}
+}
+
+func TestPaymentIntentTransitions_ShouldMatchImplementedMethods(t *testing.T) {
+	states := paymentIntentStatesForTest(t)
+
+	methods := map[PaymentIntentAction]string{
+		PaymentIntentActionRequirePaymentMethod:      "RequirePaymentMethod",
+		PaymentIntentActionRequireConfirmation:       "RequireConfirmation",
+		PaymentIntentActionRequireAction:             "RequireAction",
+		PaymentIntentActionRequireCapture:            "RequireCapture",
+		PaymentIntentActionStartProcessing:           "StartProcessing",
+		PaymentIntentActionComplete:                  "Complete",
+		PaymentIntentActionFail:                      "Fail",
+		PaymentIntentActionCancel:                    "Cancel",
+		PaymentIntentActionReselectPaymentMethodType: "ReselectPaymentMethodType",
+		PaymentIntentActionUpdateAmount:              "UpdateAmount",
+		PaymentIntentActionFailConfirmation:          "FailConfirmation",
+		PaymentIntentActionFailPayment:               "FailPayment",
+		PaymentIntentActionCapture:                   "Capture",
+		PaymentIntentActionCapturePartially:          "CapturePartially",
+		PaymentIntentActionIncrementAuthorization:    "IncrementAuthorization",
+		PaymentIntentActionExpireAuthorization:       "ExpireAuthorization",
+		PaymentIntentActionRequestRefund:             "RequestRefund",
+		PaymentIntentActionSucceedRefund:             "SucceedRefund",
+		PaymentIntentActionFailRefund:                "FailRefund",
+	}
+
+	// 表にある遷移はすべて具象型のメソッドとして実装されている
+	for _, transition := range PaymentIntentTransitions() {
+		name, ok := methods[transition.Action]
+		require.True(t, ok, "no method mapped for %s", transition.Action)
+		_, ok = reflect.TypeOf(states[transition.From]).MethodByName(name)
+		assert.True(t, ok, "%s does not implement %s", transition.From, name)
+		assert.NotEmpty(t, transition.To)
+	}
+
+	// 拡張アクションのメソッドを持つ状態は、表にも載っている
+	for status, state := range states {
+		for action, name := range methods {
+			if _, ok := interfaceMethodNames()[name]; ok {
+				continue
+			}
+			if _, ok := reflect.TypeOf(state).MethodByName(name); !ok {
+				continue
+			}
+			_, ok := FindPaymentIntentTransition(status, action)
+			assert.True(t, ok, "%s implements %s but the transition table does not list it", status, name)
+		}
+	}
+}
+
+func interfaceMethodNames() map[string]struct{} {
+	names := map[string]struct{}{}
+	paymentIntent := reflect.TypeFor[PaymentIntent]()
+	for idx := range paymentIntent.NumMethod() {
+		names[paymentIntent.Method(idx).Name] = struct{}{}
+	}
+	return names
+}
+
+func cardPaymentMethodForTest() PaymentMethod {
+	return PaymentMethod{
+		PaymentMethodType: PaymentMethodTypeCard,
+		Card: &PaymentMethodCard{
+			Number:   "4242424242424242",
+			ExpYear:  25,
+			ExpMonth: 12,
+		},
+	}
+}
+
+func paymentIntentStatesForTest(t *testing.T) map[PaymentIntentStatus]PaymentIntent {
+	t.Helper()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	step := func(_ PaymentIntentEvent, next PaymentIntent, err error) PaymentIntent {
+		t.Helper()
+		require.NoError(t, err)
+		return next
+	}
+
+	requiresPaymentMethodType := step(GeneratePaymentIntent(
+		"pi_matrix",
+		Cart{BusinessID: "biz_matrix", CartID: "cart_matrix", Items: CartItems{{ItemID: "item_1", Price: 120}}},
+		PaymentMethodTypes{PaymentMethodTypeCard},
+		now,
+	))
+	requiresPaymentMethod := step(requiresPaymentMethodType.RequirePaymentMethod(PaymentMethodTypeCard, now))
+	requiresConfirmation := step(requiresPaymentMethod.RequireConfirmation(cardPaymentMethodForTest(), PaymentCaptureMethodManual))
+	requiresAction := step(requiresConfirmation.RequireAction(now))
+	requiresCapture := step(requiresConfirmation.RequireCapture(now))
+	processing := step(requiresCapture.StartProcessing())
+	succeeded := step(processing.Complete())
+	canceled := step(requiresPaymentMethodType.Cancel(PaymentCancellationReasonAbandoned))
+
+	return map[PaymentIntentStatus]PaymentIntent{
+		PaymentIntentStatusRequiresPaymentMethodType: requiresPaymentMethodType,
+		PaymentIntentStatusRequiresPaymentMethod:     requiresPaymentMethod,
+		PaymentIntentStatusRequiresConfirmation:      requiresConfirmation,
+		PaymentIntentStatusRequiresAction:            requiresAction,
+		PaymentIntentStatusRequiresCapture:           requiresCapture,
+		PaymentIntentStatusProcessing:                processing,
+		PaymentIntentStatusSucceeded:                 succeeded,
+		PaymentIntentStatusCanceled:                  canceled,
+	}
+}
+
+func TestPaymentIntentTransitions_ShouldDriveValidation(t *testing.T) {
+	states := paymentIntentStatesForTest(t)
+
+	saved := paymentIntentTransitions
+	t.Cleanup(func() { paymentIntentTransitions = saved })
+	paymentIntentTransitions = slices.DeleteFunc(slices.Clone(saved), func(transition PaymentIntentTransition) bool {
+		return transition.From == PaymentIntentStatusProcessing && transition.Action == PaymentIntentActionComplete
+	})
+
+	// 実装があっても表から外した遷移は受け付けない
+	_, _, err := states[PaymentIntentStatusProcessing].Complete()
+	var invalid ErrInvalidTransition
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, ErrInvalidTransition{From: PaymentIntentStatusProcessing, Action: PaymentIntentActionComplete}, invalid)
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"strings"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+)
 
 type (
 	PaymentMethod struct {
@@ -25,16 +30,16 @@ func NewPaymentMethod(
 	paymentMethodType PaymentMethodType,
 	card *PaymentMethodCard,
 	payPay *PaymentMethodPayPay,
-) PaymentMethod {
+) (PaymentMethod, error) {
 	method := PaymentMethod{
 		PaymentMethodType: paymentMethodType,
 		Card:              card,
 		PayPay:            payPay,
 	}
-	if err := method.Validate(); err != nil {
-		panic(err)
+	if err := contract.Validate(method); err != nil {
+		return PaymentMethod{}, err
 	}
-	return method
+	return method, nil
 }
 
 func (p PaymentMethod) Validate() error {
@@ -77,3 +82,23 @@ func (p PaymentMethodPayPay) Validate() error {
 	}
 	return nil
 }
+
+// Masked returns a copy that is safe to keep in history: only the last four card digits survive.
+func (p PaymentMethod) Masked() PaymentMethod {
+	masked := PaymentMethod{PaymentMethodType: p.PaymentMethodType}
+	if p.Card != nil {
+		number := p.Card.Number
+		if len(number) > 4 {
+			number = strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+		}
+		masked.Card = &PaymentMethodCard{
+			Number:   number,
+			ExpYear:  p.Card.ExpYear,
+			ExpMonth: p.Card.ExpMonth,
+		}
+	}
+	if p.PayPay != nil {
+		masked.PayPay = &PaymentMethodPayPay{}
+	}
+	return masked
+}
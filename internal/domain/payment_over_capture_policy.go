@@ -0,0 +1,20 @@
+package domain
+
+import "math"
+
+// PaymentOverCapturePolicy limits how far a manual capture may exceed the authorized amount.
+// The zero value disallows over-capture.
+type PaymentOverCapturePolicy struct {
+	MaxPercent uint8
+}
+
+func (p PaymentOverCapturePolicy) MaxCapturable(authorized Money) Money {
+	if p.MaxPercent > 0 && uint64(authorized) > math.MaxUint64/uint64(p.MaxPercent) {
+		return Money(math.MaxUint64)
+	}
+	limit, err := authorized.Add(authorized * Money(p.MaxPercent) / 100)
+	if err != nil {
+		return Money(math.MaxUint64)
+	}
+	return limit
+}
@@ -0,0 +1,62 @@
+package domain
+
+import "errors"
+
+type (
+	PaymentRefundID string
+
+	PaymentRefundStatus string
+
+	PaymentRefund struct {
+		ID            PaymentRefundID
+		Amount        Money
+		Status        PaymentRefundStatus
+		FailureReason PaymentFailureReason
+	}
+
+	PaymentRefunds []PaymentRefund
+)
+
+const (
+	PaymentRefundStatusPending   PaymentRefundStatus = "pending"
+	PaymentRefundStatusSucceeded PaymentRefundStatus = "succeeded"
+	PaymentRefundStatusFailed    PaymentRefundStatus = "failed"
+)
+
+func (p PaymentRefundID) Validate() error {
+	if len(p) == 0 {
+		return errors.New("invalid payment refund id")
+	}
+	return nil
+}
+
+// AmountRefunded is the total of refunds the provider has settled.
+func (p PaymentRefunds) AmountRefunded() Money {
+	var total Money
+	for _, refund := range p {
+		if refund.Status == PaymentRefundStatusSucceeded {
+			total += refund.Amount
+		}
+	}
+	return total
+}
+
+// AmountReserved also counts pending refunds so that concurrent requests cannot exceed the captured amount.
+func (p PaymentRefunds) AmountReserved() Money {
+	var total Money
+	for _, refund := range p {
+		if refund.Status != PaymentRefundStatusFailed {
+			total += refund.Amount
+		}
+	}
+	return total
+}
+
+func (p PaymentRefunds) Find(id PaymentRefundID) (PaymentRefund, int, bool) {
+	for idx, refund := range p {
+		if refund.ID == id {
+			return refund, idx, true
+		}
+	}
+	return PaymentRefund{}, -1, false
+}
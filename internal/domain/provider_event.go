@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type (
+	ProviderEventID string
+
+	ProviderEventType string
+
+	// ProviderEvent is a payment provider notification normalized from its wire format.
+	ProviderEvent struct {
+		ID              ProviderEventID
+		Type            ProviderEventType
+		PaymentIntentID PaymentIntentID
+		// RefundID is set for refund events only.
+		RefundID PaymentRefundID
+		// Decline is set for payment_failed events when the provider classified the failure.
+		Decline    PaymentDecline
+		OccurredAt time.Time
+	}
+)
+
+const (
+	ProviderEventTypeActionCompleted  ProviderEventType = "action_completed"
+	ProviderEventTypePaymentSucceeded ProviderEventType = "payment_succeeded"
+	ProviderEventTypePaymentFailed    ProviderEventType = "payment_failed"
+	ProviderEventTypeCaptureFailed    ProviderEventType = "capture_failed"
+	ProviderEventTypeRefundSucceeded  ProviderEventType = "refund_succeeded"
+	ProviderEventTypeRefundFailed     ProviderEventType = "refund_failed"
+)
+
+func (p ProviderEventID) Validate() error {
+	if len(p) == 0 {
+		return errors.New("provider event id is empty")
+	}
+	return nil
+}
+
+func (p ProviderEventType) Validate() error {
+	switch p {
+	case ProviderEventTypeActionCompleted,
+		ProviderEventTypePaymentSucceeded,
+		ProviderEventTypePaymentFailed,
+		ProviderEventTypeCaptureFailed,
+		ProviderEventTypeRefundSucceeded,
+		ProviderEventTypeRefundFailed:
+		return nil
+	default:
+		return errors.New("unsupported provider event type")
+	}
+}
+
+func (p ProviderEvent) Validate() error {
+	if err := p.ID.Validate(); err != nil {
+		return err
+	}
+	if err := p.Type.Validate(); err != nil {
+		return err
+	}
+	if err := p.PaymentIntentID.Validate(); err != nil {
+		return err
+	}
+	if p.Type == ProviderEventTypeRefundSucceeded || p.Type == ProviderEventTypeRefundFailed {
+		if err := p.RefundID.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Type == ProviderEventTypePaymentFailed && p.Decline.Category != "" {
+		if err := p.Decline.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.OccurredAt.IsZero() {
+		return errors.New("provider event occurred at is empty")
+	}
+	return nil
+}
+
+// ReadyFor reports whether intent has reached the state this event follows. The provider does not guarantee delivery
+// order, and its webhook can overtake our own write of the request that triggered it (or even the creation of the
+// intent), so an event that is not ready has to wait for the intent to catch up. An intent that has already moved
+// past that state is ready; applying the event to it is a no-op.
+func (p ProviderEvent) ReadyFor(intent PaymentIntent) bool {
+	status := intent.Status()
+	if status == PaymentIntentStatusCanceled {
+		return true
+	}
+	switch p.Type {
+	case ProviderEventTypeActionCompleted, ProviderEventTypePaymentFailed:
+		// 3DS の失敗などは requires_action のうちに届くので、そこで適用する
+		return paymentIntentProgress(status) >= paymentIntentProgress(PaymentIntentStatusRequiresAction)
+	case ProviderEventTypeRefundSucceeded, ProviderEventTypeRefundFailed:
+		succeeded, ok := intent.(PaymentIntentSucceeded)
+		if !ok {
+			return false
+		}
+		_, _, found := succeeded.Refunds.Find(p.RefundID)
+		return found
+	default:
+		// 結果の通知は action_completed や最終キャプチャより先に届いても processing になるまで待たせる
+		return paymentIntentProgress(status) >= paymentIntentProgress(PaymentIntentStatusProcessing)
+	}
+}
+
+// paymentIntentProgress orders the non-canceled statuses along the happy path.
+func paymentIntentProgress(status PaymentIntentStatus) int {
+	switch status {
+	case PaymentIntentStatusRequiresPaymentMethodType:
+		return 0
+	case PaymentIntentStatusRequiresPaymentMethod:
+		return 1
+	case PaymentIntentStatusRequiresConfirmation:
+		return 2
+	case PaymentIntentStatusRequiresAction:
+		return 3
+	case PaymentIntentStatusRequiresCapture:
+		return 4
+	case PaymentIntentStatusProcessing:
+		return 5
+	default:
+		return 6
+	}
+}
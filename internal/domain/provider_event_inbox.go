@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type (
+	// PendingProviderEvent is a provider event parked until its PaymentIntent can take it.
+	PendingProviderEvent struct {
+		Event    ProviderEvent
+		ParkedAt time.Time
+	}
+
+	// ProviderEventInboxPolicy bounds how long parked events wait. An event parked for longer than TTL is orphaned:
+	// it is no longer replayed and is left for an operator. A zero TTL keeps events parked indefinitely.
+	ProviderEventInboxPolicy struct {
+		TTL time.Duration
+	}
+)
+
+func DefaultProviderEventInboxPolicy() ProviderEventInboxPolicy {
+	return ProviderEventInboxPolicy{TTL: 72 * time.Hour}
+}
+
+func (p ProviderEventInboxPolicy) Validate() error {
+	if p.TTL < 0 {
+		return errors.New("provider event inbox ttl must not be negative")
+	}
+	return nil
+}
+
+func (p ProviderEventInboxPolicy) IsOrphaned(pending PendingProviderEvent, now time.Time) bool {
+	if p.TTL == 0 {
+		return false
+	}
+	return !now.Before(pending.ParkedAt.Add(p.TTL))
+}
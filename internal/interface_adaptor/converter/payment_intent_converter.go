@@ -2,13 +2,19 @@ package converter
 
 import (
 	"fmt"
+	"time"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 )
 
 type PaymentIntentView struct {
 	ID                 domain.PaymentIntentID
-	SeqNr              uint8
+	SeqNr              uint64
+	BusinessID         domain.BusinessID
+	CartID             domain.CartID
+	CartItems          domain.CartItems
+	CreatedAt          time.Time
+	Attempts           domain.PaymentAttempts
 	Status             string
 	Amount             domain.Money
 	PaymentMethodTypes domain.PaymentMethodTypes
@@ -16,6 +22,12 @@ type PaymentIntentView struct {
 	PaymentMethod      domain.PaymentMethod
 	CaptureMethod      domain.PaymentCaptureMethod
 	FailureReason      domain.PaymentFailureReason
+	CancellationReason domain.PaymentCancellationReason
+	AmountCaptured     domain.Money
+	AuthorizedAt       time.Time
+	Refunds            domain.PaymentRefunds
+	AmountRefunded     domain.Money
+	AmountRefundable   domain.Money
 }
 
 func ToPaymentIntentView(intent domain.PaymentIntent) (PaymentIntentView, error) {
@@ -24,7 +36,12 @@ func ToPaymentIntentView(intent domain.PaymentIntent) (PaymentIntentView, error)
 		return PaymentIntentView{
 			ID:                 v.ID,
 			SeqNr:              v.SeqNr,
-			Status:             "requires_payment_method_type",
+			BusinessID:         v.BusinessID,
+			CartID:             v.CartID,
+			CartItems:          v.CartItems,
+			CreatedAt:          v.CreatedAt,
+			Attempts:           v.Attempts,
+			Status:             string(domain.PaymentIntentStatusRequiresPaymentMethodType),
 			Amount:             v.Amount,
 			PaymentMethodTypes: v.PaymentMethodTypes,
 		}, nil
@@ -32,7 +49,12 @@ func ToPaymentIntentView(intent domain.PaymentIntent) (PaymentIntentView, error)
 		return PaymentIntentView{
 			ID:                v.ID,
 			SeqNr:             v.SeqNr,
-			Status:            "requires_payment_method",
+			BusinessID:        v.BusinessID,
+			CartID:            v.CartID,
+			CartItems:         v.CartItems,
+			CreatedAt:         v.CreatedAt,
+			Attempts:          v.Attempts,
+			Status:            string(domain.PaymentIntentStatusRequiresPaymentMethod),
 			Amount:            v.Amount,
 			PaymentMethodType: v.PaymentMethodType,
 			FailureReason:     v.FailureReason,
@@ -41,7 +63,12 @@ func ToPaymentIntentView(intent domain.PaymentIntent) (PaymentIntentView, error)
 		return PaymentIntentView{
 			ID:            v.ID,
 			SeqNr:         v.SeqNr,
-			Status:        "requires_confirmation",
+			BusinessID:    v.BusinessID,
+			CartID:        v.CartID,
+			CartItems:     v.CartItems,
+			CreatedAt:     v.CreatedAt,
+			Attempts:      v.Attempts,
+			Status:        string(domain.PaymentIntentStatusRequiresConfirmation),
 			Amount:        v.Amount,
 			PaymentMethod: v.PaymentMethod,
 			CaptureMethod: v.CaptureMethod,
@@ -50,45 +77,78 @@ func ToPaymentIntentView(intent domain.PaymentIntent) (PaymentIntentView, error)
 		return PaymentIntentView{
 			ID:            v.ID,
 			SeqNr:         v.SeqNr,
-			Status:        "requires_action",
+			BusinessID:    v.BusinessID,
+			CartID:        v.CartID,
+			CartItems:     v.CartItems,
+			CreatedAt:     v.CreatedAt,
+			Attempts:      v.Attempts,
+			Status:        string(domain.PaymentIntentStatusRequiresAction),
 			Amount:        v.Amount,
 			PaymentMethod: v.PaymentMethod,
 			CaptureMethod: v.CaptureMethod,
 		}, nil
 	case domain.PaymentIntentRequiresCapture:
 		return PaymentIntentView{
-			ID:            v.ID,
-			SeqNr:         v.SeqNr,
-			Status:        "requires_capture",
-			Amount:        v.Amount,
-			PaymentMethod: v.PaymentMethod,
-			CaptureMethod: v.CaptureMethod,
+			ID:             v.ID,
+			SeqNr:          v.SeqNr,
+			BusinessID:     v.BusinessID,
+			CartID:         v.CartID,
+			CartItems:      v.CartItems,
+			CreatedAt:      v.CreatedAt,
+			Attempts:       v.Attempts,
+			Status:         string(domain.PaymentIntentStatusRequiresCapture),
+			Amount:         v.Amount,
+			AuthorizedAt:   v.AuthorizedAt,
+			AmountCaptured: v.AmountCaptured,
+			PaymentMethod:  v.PaymentMethod,
+			CaptureMethod:  v.CaptureMethod,
 		}, nil
 	case domain.PaymentIntentProcessing:
 		return PaymentIntentView{
-			ID:            v.ID,
-			SeqNr:         v.SeqNr,
-			Status:        "processing",
-			Amount:        v.Amount,
-			PaymentMethod: v.PaymentMethod,
-			CaptureMethod: v.CaptureMethod,
+			ID:             v.ID,
+			SeqNr:          v.SeqNr,
+			BusinessID:     v.BusinessID,
+			CartID:         v.CartID,
+			CartItems:      v.CartItems,
+			CreatedAt:      v.CreatedAt,
+			Attempts:       v.Attempts,
+			Status:         string(domain.PaymentIntentStatusProcessing),
+			Amount:         v.Amount,
+			AmountCaptured: v.AmountCaptured,
+			PaymentMethod:  v.PaymentMethod,
+			CaptureMethod:  v.CaptureMethod,
 		}, nil
 	case domain.PaymentIntentSucceeded:
 		return PaymentIntentView{
-			ID:            v.ID,
-			SeqNr:         v.SeqNr,
-			Status:        "succeeded",
-			Amount:        v.Amount,
-			PaymentMethod: v.PaymentMethod,
+			ID:               v.ID,
+			SeqNr:            v.SeqNr,
+			BusinessID:       v.BusinessID,
+			CartID:           v.CartID,
+			CartItems:        v.CartItems,
+			CreatedAt:        v.CreatedAt,
+			Attempts:         v.Attempts,
+			Status:           string(domain.PaymentIntentStatusSucceeded),
+			Amount:           v.Amount,
+			AmountCaptured:   v.AmountCaptured,
+			PaymentMethod:    v.PaymentMethod,
+			Refunds:          v.Refunds,
+			AmountRefunded:   v.Refunds.AmountRefunded(),
+			AmountRefundable: v.AmountRefundable(),
 		}, nil
 	case domain.PaymentIntentCanceled:
 		return PaymentIntentView{
-			ID:            v.ID,
-			SeqNr:         v.SeqNr,
-			Status:        "canceled",
-			Amount:        v.Amount,
-			PaymentMethod: v.PaymentMethod,
-			FailureReason: v.FailureReason,
+			ID:                 v.ID,
+			SeqNr:              v.SeqNr,
+			BusinessID:         v.BusinessID,
+			CartID:             v.CartID,
+			CartItems:          v.CartItems,
+			CreatedAt:          v.CreatedAt,
+			Attempts:           v.Attempts,
+			Status:             string(domain.PaymentIntentStatusCanceled),
+			Amount:             v.Amount,
+			PaymentMethod:      v.PaymentMethod,
+			FailureReason:      v.FailureReason,
+			CancellationReason: v.CancellationReason,
 		}, nil
 	default:
 		return PaymentIntentView{}, fmt.Errorf("unsupported payment intent state %T", intent)
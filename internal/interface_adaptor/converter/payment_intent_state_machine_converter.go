@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+type PaymentIntentDiagramFormat string
+
+const (
+	PaymentIntentDiagramFormatMermaid PaymentIntentDiagramFormat = "mermaid"
+	PaymentIntentDiagramFormatDOT     PaymentIntentDiagramFormat = "dot"
+)
+
+func ToPaymentIntentDiagram(format PaymentIntentDiagramFormat, transitions []domain.PaymentIntentTransition) (string, error) {
+	switch format {
+	case PaymentIntentDiagramFormatMermaid:
+		return ToPaymentIntentMermaid(transitions), nil
+	case PaymentIntentDiagramFormatDOT:
+		return ToPaymentIntentDOT(transitions), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported diagram format %q", domain.ErrInvalidArgument, format)
+	}
+}
+
+func ToPaymentIntentMermaid(transitions []domain.PaymentIntentTransition) string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	fmt.Fprintf(&b, "    [*] --> %s\n", domain.PaymentIntentStatusRequiresPaymentMethodType)
+	for _, transition := range transitions {
+		for _, to := range transition.To {
+			fmt.Fprintf(&b, "    %s --> %s: %s\n", transition.From, to, transition.Action)
+		}
+	}
+	for _, status := range paymentIntentTerminalStatuses() {
+		fmt.Fprintf(&b, "    %s --> [*]\n", status)
+	}
+	return b.String()
+}
+
+func ToPaymentIntentDOT(transitions []domain.PaymentIntentTransition) string {
+	var b strings.Builder
+	b.WriteString("digraph PaymentIntent {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    start [shape=point];\n")
+	fmt.Fprintf(&b, "    start -> %q;\n", domain.PaymentIntentStatusRequiresPaymentMethodType)
+	for _, status := range paymentIntentTerminalStatuses() {
+		fmt.Fprintf(&b, "    %q [shape=doublecircle];\n", status)
+	}
+	for _, transition := range transitions {
+		for _, to := range transition.To {
+			fmt.Fprintf(&b, "    %q -> %q [label=%q];\n", transition.From, to, transition.Action)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func paymentIntentTerminalStatuses() []domain.PaymentIntentStatus {
+	return []domain.PaymentIntentStatus{
+		domain.PaymentIntentStatusSucceeded,
+		domain.PaymentIntentStatusCanceled,
+	}
+}
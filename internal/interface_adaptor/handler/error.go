@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+// errUnauthorized rejects callers that are not allowed to use an endpoint.
+var errUnauthorized = errors.New("unauthorized")
+
+type (
+	errorResponse struct {
+		Error errorBody `json:"error"`
+		// 決済手段の拒否などで状態が変わった場合は、変化後の PaymentIntent も返す
+		PaymentIntent *paymentIntentResponse `json:"payment_intent,omitempty"`
+	}
+
+	errorBody struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+)
+
+// statusFromError maps the domain error taxonomy onto HTTP. Anything unclassified is an internal error.
+func statusFromError(err error) (int, string) {
+	if _, ok := service.DeclineFrom(err); ok {
+		return http.StatusPaymentRequired, "payment_declined"
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhookSignature), errors.Is(err, errUnauthorized):
+		return http.StatusUnauthorized, "unauthorized"
+	case errors.Is(err, domain.ErrInvalidArgument), errors.Is(err, service.ErrPaymentMethodProviderNotFound):
+		return http.StatusBadRequest, "invalid_argument"
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, domain.ErrInvalidStateTransition):
+		return http.StatusConflict, "invalid_state_transition"
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict, "conflict"
+	default:
+		return http.StatusInternalServerError, "internal"
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error, intent domain.PaymentIntent) {
+	status, code := statusFromError(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+		message = http.StatusText(status)
+	}
+
+	response := errorResponse{Error: errorBody{Code: code, Message: message}}
+	if intent != nil {
+		if body, convErr := toPaymentIntentResponse(intent); convErr == nil {
+			response.PaymentIntent = &body
+		}
+	}
+	writeJSON(w, status, response)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"net/http"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/usecase"
+)
+
+type (
+	UseCases struct {
+		CreateBusiness          usecase.CreateBusinessUseCase
+		CreateCart              usecase.CreateCartUseCase
+		ConfirmCart             usecase.ConfirmCartUseCase
+		InitializePaymentIntent usecase.InitializePaymentIntentUseCase
+		SelectPaymentMethod     usecase.SelectPaymentMethodUseCase
+		ProvidePaymentMethod    usecase.ProvidePaymentMethodUseCase
+		ConfirmPaymentIntent    usecase.ConfirmPaymentIntentUseCase
+		CapturePaymentIntent    usecase.CapturePaymentIntentUseCase
+		GetPaymentIntent        usecase.GetPaymentIntentUseCase
+
+		ApplyPaymentIntentEvent    usecase.ApplyPaymentIntentEventUseCase
+		ListOrphanedProviderEvents usecase.ListOrphanedProviderEventsUseCase
+	}
+
+	handler struct {
+		useCases UseCases
+		webhook  Webhook
+		operator Operator
+	}
+
+	businessResponse struct {
+		ID                 string   `json:"id"`
+		Name               string   `json:"name"`
+		PaymentMethodTypes []string `json:"payment_method_types"`
+	}
+
+	cartResponse struct {
+		BusinessID string         `json:"business_id"`
+		CartID     string         `json:"cart_id"`
+		Items      []cartItemJSON `json:"items"`
+		Amount     uint64         `json:"amount"`
+	}
+
+	cartTokenResponse struct {
+		CartToken string `json:"cart_token"`
+	}
+)
+
+func NewHandler(useCases UseCases, webhook Webhook, operator Operator) http.Handler {
+	if useCases.CreateBusiness == nil ||
+		useCases.CreateCart == nil ||
+		useCases.ConfirmCart == nil ||
+		useCases.InitializePaymentIntent == nil ||
+		useCases.SelectPaymentMethod == nil ||
+		useCases.ProvidePaymentMethod == nil ||
+		useCases.ConfirmPaymentIntent == nil ||
+		useCases.CapturePaymentIntent == nil ||
+		useCases.GetPaymentIntent == nil ||
+		useCases.ApplyPaymentIntentEvent == nil ||
+		useCases.ListOrphanedProviderEvents == nil {
+		panic("useCases is incomplete")
+	}
+	if webhook.Verifier == nil {
+		panic("webhook is incomplete")
+	}
+	if operator.Token == "" {
+		panic("operator is incomplete")
+	}
+
+	document, err := parseOpenAPIDocument()
+	if err != nil {
+		panic(err)
+	}
+
+	h := &handler{useCases: useCases, webhook: webhook, operator: operator}
+	mux := http.NewServeMux()
+	for _, route := range h.routes() {
+		// openapi.yaml にない経路は登録しない
+		validator, err := document.requestValidator(route.Method, route.Path)
+		if err != nil {
+			panic(err)
+		}
+		mux.HandleFunc(route.Pattern, validator.wrap(route.authenticate, route.Handle))
+	}
+	return mux
+}
+
+func (h *handler) createBusiness(w http.ResponseWriter, r *http.Request) {
+	var request createBusinessRequest
+	if err := decodeJSON(w, r, &request); err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	output, err := h.useCases.CreateBusiness.Execute(r.Context(), usecase.CreateBusinessUseCaseInput{
+		Name:               request.Name,
+		PaymentMethodTypes: toPaymentMethodTypes(request.PaymentMethodTypes),
+	})
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	response := businessResponse{ID: string(output.Business.ID), Name: output.Business.Name}
+	for _, methodType := range output.Business.PaymentMethodTypes {
+		response.PaymentMethodTypes = append(response.PaymentMethodTypes, string(methodType))
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func (h *handler) createCart(w http.ResponseWriter, r *http.Request) {
+	var request createCartRequest
+	if err := decodeJSON(w, r, &request); err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	output, err := h.useCases.CreateCart.Execute(r.Context(), usecase.CreateCartUseCaseInput{
+		BusinessID: domain.BusinessID(r.PathValue("businessID")),
+		Items:      toCartItems(request.Items),
+	})
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, cartResponse{
+		BusinessID: string(output.Cart.BusinessID),
+		CartID:     string(output.Cart.CartID),
+		Items:      toCartItemsJSON(output.Cart.Items),
+		Amount:     uint64(output.Cart.CalculateAmount()),
+	})
+}
+
+// confirmCart seals the cart stored by createCart into a signed token.
+func (h *handler) confirmCart(w http.ResponseWriter, r *http.Request) {
+	output, err := h.useCases.ConfirmCart.Execute(r.Context(), usecase.ConfirmCartUseCaseInput{
+		BusinessID: domain.BusinessID(r.PathValue("businessID")),
+		CartID:     domain.CartID(r.PathValue("cartID")),
+	})
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cartTokenResponse{CartToken: output.Token.Value})
+}
+
+func (h *handler) initializePaymentIntent(w http.ResponseWriter, r *http.Request) {
+	var request initializePaymentIntentRequest
+	if err := decodeJSON(w, r, &request); err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	output, err := h.useCases.InitializePaymentIntent.Execute(r.Context(), usecase.InitializePaymentIntentUseCaseInput{
+		CartToken: service.SignedToken{Value: request.CartToken},
+	})
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	writePaymentIntent(w, r, http.StatusCreated, output.PaymentIntent)
+}
+
+func (h *handler) selectPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	var request selectPaymentMethodRequest
+	if err := decodeJSON(w, r, &request); err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	output, err := h.useCases.SelectPaymentMethod.Execute(r.Context(), usecase.SelectPaymentMethodUseCaseInput{
+		PaymentIntentID:   paymentIntentIDFrom(r),
+		PaymentMethodType: domain.PaymentMethodType(request.PaymentMethodType),
+	})
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	writePaymentIntent(w, r, http.StatusOK, output.PaymentIntent)
+}
+
+func (h *handler) providePaymentMethod(w http.ResponseWriter, r *http.Request) {
+	var request providePaymentMethodRequest
+	if err := decodeJSON(w, r, &request); err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	output, err := h.useCases.ProvidePaymentMethod.Execute(r.Context(), usecase.ProvidePaymentMethodUseCaseInput{
+		PaymentIntentID: paymentIntentIDFrom(r),
+		PaymentMethod:   request.PaymentMethod.toDomain(),
+		CaptureMethod:   domain.PaymentCaptureMethod(request.CaptureMethod),
+	})
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	writePaymentIntent(w, r, http.StatusOK, output.PaymentIntent)
+}
+
+func (h *handler) confirmPaymentIntent(w http.ResponseWriter, r *http.Request) {
+	output, err := h.useCases.ConfirmPaymentIntent.Execute(r.Context(), usecase.ConfirmPaymentIntentUseCaseInput{
+		PaymentIntentID: paymentIntentIDFrom(r),
+	})
+	if err != nil {
+		var intent domain.PaymentIntent
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+		writeError(w, r, err, intent)
+		return
+	}
+
+	writePaymentIntent(w, r, http.StatusOK, output.PaymentIntent)
+}
+
+func (h *handler) capturePaymentIntent(w http.ResponseWriter, r *http.Request) {
+	var request capturePaymentIntentRequest
+	if err := decodeJSON(w, r, &request); err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	output, err := h.useCases.CapturePaymentIntent.Execute(r.Context(), usecase.CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: paymentIntentIDFrom(r),
+		AmountToCapture: domain.Money(request.AmountToCapture),
+		MultiCapture:    request.MultiCapture,
+	})
+	if err != nil {
+		var intent domain.PaymentIntent
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+		writeError(w, r, err, intent)
+		return
+	}
+
+	writePaymentIntent(w, r, http.StatusOK, output.PaymentIntent)
+}
+
+func (h *handler) getPaymentIntent(w http.ResponseWriter, r *http.Request) {
+	output, err := h.useCases.GetPaymentIntent.Execute(r.Context(), usecase.GetPaymentIntentUseCaseInput{
+		PaymentIntentID: paymentIntentIDFrom(r),
+	})
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	writePaymentIntent(w, r, http.StatusOK, output.PaymentIntent)
+}
+
+func paymentIntentIDFrom(r *http.Request) domain.PaymentIntentID {
+	return domain.PaymentIntentID(r.PathValue("paymentIntentID"))
+}
+
+func writePaymentIntent(w http.ResponseWriter, r *http.Request, status int, intent domain.PaymentIntent) {
+	response, err := toPaymentIntentResponse(intent)
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+	writeJSON(w, status, response)
+}
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+type (
+	openAPIDocument struct {
+		Paths      map[string]map[string]any `yaml:"paths"`
+		Components struct {
+			Schemas map[string]*jsonSchema `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+
+	openAPIOperation struct {
+		RequestBody *struct {
+			Required bool `yaml:"required"`
+			Content  map[string]struct {
+				Schema *jsonSchema `yaml:"schema"`
+			} `yaml:"content"`
+		} `yaml:"requestBody"`
+	}
+
+	// jsonSchema is the subset of OpenAPI schema objects that openapi.yaml uses for request bodies.
+	jsonSchema struct {
+		Ref                  string                 `yaml:"$ref"`
+		Type                 string                 `yaml:"type"`
+		Properties           map[string]*jsonSchema `yaml:"properties"`
+		Required             []string               `yaml:"required"`
+		AdditionalProperties *bool                  `yaml:"additionalProperties"`
+		Items                *jsonSchema            `yaml:"items"`
+		Enum                 []string               `yaml:"enum"`
+		Minimum              *float64               `yaml:"minimum"`
+		Maximum              *float64               `yaml:"maximum"`
+		MinLength            *int                   `yaml:"minLength"`
+		MinItems             *int                   `yaml:"minItems"`
+	}
+
+	// requestValidator checks one operation's request body before the handler decodes it.
+	requestValidator struct {
+		schema   *jsonSchema
+		required bool
+		schemas  map[string]*jsonSchema
+	}
+)
+
+var openAPIMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// OpenAPISpec returns the embedded OpenAPI document.
+func OpenAPISpec() []byte {
+	return slices.Clone(openAPISpec)
+}
+
+// OpenAPIOperations lists the "<METHOD> <path>" pairs the document describes.
+func OpenAPIOperations() ([]string, error) {
+	document, err := parseOpenAPIDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []string
+	for path, item := range document.Paths {
+		for key := range item {
+			method := strings.ToUpper(key)
+			if slices.Contains(openAPIMethods, method) {
+				operations = append(operations, method+" "+path)
+			}
+		}
+	}
+	sort.Strings(operations)
+	return operations, nil
+}
+
+func parseOpenAPIDocument() (openAPIDocument, error) {
+	var document openAPIDocument
+	if err := yaml.Unmarshal(openAPISpec, &document); err != nil {
+		return openAPIDocument{}, fmt.Errorf("parse openapi.yaml: %w", err)
+	}
+	return document, nil
+}
+
+func (d openAPIDocument) requestValidator(method, path string) (requestValidator, error) {
+	raw, ok := d.Paths[path][strings.ToLower(method)]
+	if !ok {
+		return requestValidator{}, fmt.Errorf("openapi.yaml does not describe %s %s", method, path)
+	}
+
+	// 操作ごとに型付きで読み直す
+	encoded, err := yaml.Marshal(raw)
+	if err != nil {
+		return requestValidator{}, err
+	}
+	var operation openAPIOperation
+	if err := yaml.Unmarshal(encoded, &operation); err != nil {
+		return requestValidator{}, err
+	}
+
+	validator := requestValidator{schemas: d.Components.Schemas}
+	if operation.RequestBody != nil {
+		content, ok := operation.RequestBody.Content["application/json"]
+		if !ok {
+			return requestValidator{}, fmt.Errorf("%s %s must accept application/json", method, path)
+		}
+		validator.schema = content.Schema
+		validator.required = operation.RequestBody.Required
+		// 仕様の誤りは起動時に落とし、リクエスト時にはクライアントの誤りだけを返す
+		if err := validator.checkSchema("body", validator.schema, map[*jsonSchema]bool{}); err != nil {
+			return requestValidator{}, fmt.Errorf("%s %s: %w", method, path, err)
+		}
+	}
+	return validator, nil
+}
+
+// checkSchema walks a request schema once so that validateValue never meets a schema it cannot apply.
+func (v requestValidator) checkSchema(at string, schema *jsonSchema, visited map[*jsonSchema]bool) error {
+	schema, err := v.resolve(schema)
+	if err != nil {
+		return fmt.Errorf("%s: %w", at, err)
+	}
+	if visited[schema] {
+		return nil
+	}
+	visited[schema] = true
+
+	switch schema.Type {
+	case "object":
+		for name, property := range schema.Properties {
+			if err := v.checkSchema(at+"."+name, property, visited); err != nil {
+				return err
+			}
+		}
+	case "array":
+		return v.checkSchema(at+"[]", schema.Items, visited)
+	case "integer":
+		for _, bound := range []*float64{schema.Minimum, schema.Maximum} {
+			if bound != nil && (*bound != math.Trunc(*bound) || *bound < math.MinInt64 || *bound >= math.MaxInt64) {
+				return fmt.Errorf("%s: integer bound %v is not an int64", at, *bound)
+			}
+		}
+	case "string", "boolean":
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", at, schema.Type)
+	}
+	return nil
+}
+
+func (v requestValidator) wrap(authenticate requestAuthenticator, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+		if err != nil {
+			writeError(w, r, fmt.Errorf("%w: request body is too large", domain.ErrInvalidArgument), nil)
+			return
+		}
+		if authenticate != nil {
+			if err := authenticate(r, body); err != nil {
+				writeError(w, r, err, nil)
+				return
+			}
+		}
+		if err := v.validate(body); err != nil {
+			writeError(w, r, err, nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r)
+	}
+}
+
+func (v requestValidator) validate(body []byte) error {
+	empty := len(bytes.TrimSpace(body)) == 0
+	if v.schema == nil {
+		if !empty {
+			return fmt.Errorf("%w: request body is not accepted", domain.ErrInvalidArgument)
+		}
+		return nil
+	}
+	if empty {
+		if v.required {
+			return fmt.Errorf("%w: request body is required", domain.ErrInvalidArgument)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%w: malformed json: %v", domain.ErrInvalidArgument, err)
+	}
+	if err := v.validateValue("body", v.schema, value); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidArgument, err)
+	}
+	return nil
+}
+
+func (v requestValidator) validateValue(at string, schema *jsonSchema, value any) error {
+	schema, err := v.resolve(schema)
+	if err != nil {
+		panic(fmt.Sprintf("schema at %s was not checked at registration: %v", at, err))
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s must be an object", at)
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s.%s is required", at, name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return fmt.Errorf("%s.%s is not allowed", at, name)
+				}
+				continue
+			}
+			if err := v.validateValue(at+"."+name, property, object[name]); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s must be an array", at)
+		}
+		if schema.MinItems != nil && len(array) < *schema.MinItems {
+			return fmt.Errorf("%s must have at least %d items", at, *schema.MinItems)
+		}
+		for i, item := range array {
+			if err := v.validateValue(fmt.Sprintf("%s[%d]", at, i), schema.Items, item); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string", at)
+		}
+		if schema.MinLength != nil && len(s) < *schema.MinLength {
+			return fmt.Errorf("%s must be at least %d characters", at, *schema.MinLength)
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, s) {
+			return fmt.Errorf("%s must be one of %s", at, strings.Join(schema.Enum, ", "))
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok || strings.ContainsAny(number.String(), ".eE") {
+			return fmt.Errorf("%s must be an integer", at)
+		}
+		// float64 では 2^53 を超える値の比較が丸められるので、整数のまま比べる
+		n, err := number.Int64()
+		if err != nil {
+			return fmt.Errorf("%s is out of range", at)
+		}
+		if schema.Minimum != nil && n < int64(*schema.Minimum) {
+			return fmt.Errorf("%s must be >= %v", at, *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > int64(*schema.Maximum) {
+			return fmt.Errorf("%s must be <= %v", at, *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s must be a boolean", at)
+		}
+	default:
+		panic(fmt.Sprintf("schema at %s was not checked at registration: unsupported type %q", at, schema.Type))
+	}
+	return nil
+}
+
+func (v requestValidator) resolve(schema *jsonSchema) (*jsonSchema, error) {
+	if schema == nil {
+		return nil, errors.New("schema is missing")
+	}
+	if schema.Ref == "" {
+		return schema, nil
+	}
+	name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q", schema.Ref)
+	}
+	resolved, ok := v.schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %q", name)
+	}
+	return v.resolve(resolved)
+}
+
+func (h *handler) getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(openAPISpec)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRequestValidator_ShouldRejectBrokenSchemasAtRegistration(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name:    "unknown ref",
+			schema:  `{$ref: "#/components/schemas/Missing"}`,
+			wantErr: "body: unknown schema",
+		},
+		{
+			name:    "external ref",
+			schema:  `{$ref: "other.yaml#/Item"}`,
+			wantErr: "body: unsupported $ref",
+		},
+		{
+			name:    "nested unsupported type",
+			schema:  `{type: object, properties: {price: {type: number}}}`,
+			wantErr: `body.price: unsupported schema type "number"`,
+		},
+		{
+			name:    "array without items",
+			schema:  `{type: array}`,
+			wantErr: "body[]: schema is missing",
+		},
+		{
+			name:    "fractional integer bound",
+			schema:  `{type: integer, minimum: 0.5}`,
+			wantErr: "body: integer bound 0.5 is not an int64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := `
+paths:
+  /items:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: ` + tt.schema + `
+components:
+  schemas:
+    Item: {type: object}
+`
+			var document openAPIDocument
+			require.NoError(t, yaml.Unmarshal([]byte(spec), &document))
+
+			_, err := document.requestValidator("POST", "/items")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "POST /items: "+tt.wantErr)
+		})
+	}
+}
+
+func TestRequestValidator_ShouldAcceptEmbeddedSpec(t *testing.T) {
+	document, err := parseOpenAPIDocument()
+	require.NoError(t, err)
+
+	operations, err := OpenAPIOperations()
+	require.NoError(t, err)
+	for _, operation := range operations {
+		method, path, _ := strings.Cut(operation, " ")
+		_, err := document.requestValidator(method, path)
+		assert.NoError(t, err, operation)
+	}
+}
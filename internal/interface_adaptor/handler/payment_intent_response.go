@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"time"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/converter"
+)
+
+type (
+	paymentIntentResponse struct {
+		ID                 string                   `json:"id"`
+		SeqNr              uint64                   `json:"seq_nr"`
+		Status             string                   `json:"status"`
+		BusinessID         string                   `json:"business_id"`
+		CartID             string                   `json:"cart_id"`
+		Items              []cartItemJSON           `json:"items"`
+		CreatedAt          time.Time                `json:"created_at"`
+		Amount             uint64                   `json:"amount"`
+		PaymentMethodTypes []string                 `json:"payment_method_types,omitempty"`
+		PaymentMethodType  string                   `json:"payment_method_type,omitempty"`
+		PaymentMethod      *paymentMethodResponse   `json:"payment_method,omitempty"`
+		CaptureMethod      string                   `json:"capture_method,omitempty"`
+		FailureReason      string                   `json:"failure_reason,omitempty"`
+		CancellationReason string                   `json:"cancellation_reason,omitempty"`
+		AmountCaptured     uint64                   `json:"amount_captured"`
+		AuthorizedAt       *time.Time               `json:"authorized_at,omitempty"`
+		AmountRefunded     uint64                   `json:"amount_refunded"`
+		AmountRefundable   uint64                   `json:"amount_refundable"`
+		Refunds            []paymentRefundResponse  `json:"refunds,omitempty"`
+		Attempts           []paymentAttemptResponse `json:"attempts,omitempty"`
+	}
+
+	// paymentMethodResponse never carries the full card number; it is built from domain.PaymentMethod.Masked.
+	paymentMethodResponse struct {
+		Type   string        `json:"type"`
+		Card   *cardResponse `json:"card,omitempty"`
+		PayPay *struct{}     `json:"paypay,omitempty"`
+	}
+
+	cardResponse struct {
+		Number   string `json:"number"`
+		ExpYear  uint8  `json:"exp_year"`
+		ExpMonth uint8  `json:"exp_month"`
+	}
+
+	paymentRefundResponse struct {
+		ID            string `json:"id"`
+		Amount        uint64 `json:"amount"`
+		Status        string `json:"status"`
+		FailureReason string `json:"failure_reason,omitempty"`
+	}
+
+	paymentAttemptResponse struct {
+		PaymentMethodType string    `json:"payment_method_type"`
+		Outcome           string    `json:"outcome"`
+		FailureReason     string    `json:"failure_reason,omitempty"`
+		DeclineCategory   string    `json:"decline_category,omitempty"`
+		CustomerMessage   string    `json:"customer_message,omitempty"`
+		AttemptedAt       time.Time `json:"attempted_at"`
+	}
+)
+
+func toPaymentIntentResponse(intent domain.PaymentIntent) (paymentIntentResponse, error) {
+	view, err := converter.ToPaymentIntentView(intent)
+	if err != nil {
+		return paymentIntentResponse{}, err
+	}
+
+	response := paymentIntentResponse{
+		ID:                 string(view.ID),
+		SeqNr:              view.SeqNr,
+		Status:             view.Status,
+		BusinessID:         string(view.BusinessID),
+		CartID:             string(view.CartID),
+		Items:              toCartItemsJSON(view.CartItems),
+		CreatedAt:          view.CreatedAt,
+		Amount:             uint64(view.Amount),
+		PaymentMethodType:  string(view.PaymentMethodType),
+		PaymentMethod:      toPaymentMethodResponse(view.PaymentMethod),
+		CaptureMethod:      string(view.CaptureMethod),
+		FailureReason:      string(view.FailureReason),
+		CancellationReason: string(view.CancellationReason),
+		AmountCaptured:     uint64(view.AmountCaptured),
+		AmountRefunded:     uint64(view.AmountRefunded),
+		AmountRefundable:   uint64(view.AmountRefundable),
+	}
+	for _, methodType := range view.PaymentMethodTypes {
+		response.PaymentMethodTypes = append(response.PaymentMethodTypes, string(methodType))
+	}
+	if !view.AuthorizedAt.IsZero() {
+		response.AuthorizedAt = &view.AuthorizedAt
+	}
+	for _, refund := range view.Refunds {
+		response.Refunds = append(response.Refunds, paymentRefundResponse{
+			ID:            string(refund.ID),
+			Amount:        uint64(refund.Amount),
+			Status:        string(refund.Status),
+			FailureReason: string(refund.FailureReason),
+		})
+	}
+	for _, attempt := range view.Attempts {
+		response.Attempts = append(response.Attempts, paymentAttemptResponse{
+			PaymentMethodType: string(attempt.PaymentMethodType),
+			Outcome:           string(attempt.Outcome),
+			FailureReason:     string(attempt.FailureReason),
+			DeclineCategory:   string(attempt.DeclineCategory),
+			CustomerMessage:   attempt.CustomerMessage,
+			AttemptedAt:       attempt.AttemptedAt,
+		})
+	}
+	return response, nil
+}
+
+func toPaymentMethodResponse(method domain.PaymentMethod) *paymentMethodResponse {
+	if method.PaymentMethodType == "" {
+		return nil
+	}
+	masked := method.Masked()
+	response := &paymentMethodResponse{Type: string(masked.PaymentMethodType)}
+	if masked.Card != nil {
+		response.Card = &cardResponse{
+			Number:   masked.Card.Number,
+			ExpYear:  masked.Card.ExpYear,
+			ExpMonth: masked.Card.ExpMonth,
+		}
+	}
+	if masked.PayPay != nil {
+		response.PayPay = &struct{}{}
+	}
+	return response
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+const maxRequestBodyBytes = 1 << 20
+
+type (
+	cartItemJSON struct {
+		ItemID string `json:"item_id"`
+		Price  uint64 `json:"price"`
+	}
+
+	createBusinessRequest struct {
+		Name               string   `json:"name"`
+		PaymentMethodTypes []string `json:"payment_method_types"`
+	}
+
+	createCartRequest struct {
+		Items []cartItemJSON `json:"items"`
+	}
+
+	initializePaymentIntentRequest struct {
+		CartToken string `json:"cart_token"`
+	}
+
+	selectPaymentMethodRequest struct {
+		PaymentMethodType string `json:"payment_method_type"`
+	}
+
+	providePaymentMethodRequest struct {
+		PaymentMethod paymentMethodRequest `json:"payment_method"`
+		CaptureMethod string               `json:"capture_method"`
+	}
+
+	paymentMethodRequest struct {
+		Type   string         `json:"type"`
+		Card   *cardRequest   `json:"card,omitempty"`
+		PayPay *payPayRequest `json:"paypay,omitempty"`
+	}
+
+	cardRequest struct {
+		Number   string `json:"number"`
+		ExpYear  uint8  `json:"exp_year"`
+		ExpMonth uint8  `json:"exp_month"`
+	}
+
+	payPayRequest struct {
+		AuthorizationURL string `json:"authorization_url"`
+	}
+
+	capturePaymentIntentRequest struct {
+		AmountToCapture uint64 `json:"amount_to_capture"`
+		MultiCapture    bool   `json:"multi_capture"`
+	}
+)
+
+// decodeJSON reads a single JSON object. An empty body is accepted for endpoints whose fields are all optional.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	if err != nil {
+		return fmt.Errorf("%w: request body is too large", domain.ErrInvalidArgument)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: malformed json: %v", domain.ErrInvalidArgument, err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: request body must be a single json object", domain.ErrInvalidArgument)
+	}
+	return nil
+}
+
+func toCartItems(items []cartItemJSON) domain.CartItems {
+	cartItems := make(domain.CartItems, len(items))
+	for i, item := range items {
+		cartItems[i] = domain.CartItem{ItemID: domain.ItemID(item.ItemID), Price: domain.ItemPrice(item.Price)}
+	}
+	return cartItems
+}
+
+func toCartItemsJSON(items domain.CartItems) []cartItemJSON {
+	response := make([]cartItemJSON, len(items))
+	for i, item := range items {
+		response[i] = cartItemJSON{ItemID: string(item.ItemID), Price: uint64(item.Price)}
+	}
+	return response
+}
+
+func toPaymentMethodTypes(types []string) domain.PaymentMethodTypes {
+	methodTypes := make(domain.PaymentMethodTypes, len(types))
+	for i, methodType := range types {
+		methodTypes[i] = domain.PaymentMethodType(methodType)
+	}
+	return methodTypes
+}
+
+func (p paymentMethodRequest) toDomain() domain.PaymentMethod {
+	method := domain.PaymentMethod{PaymentMethodType: domain.PaymentMethodType(p.Type)}
+	if p.Card != nil {
+		method.Card = &domain.PaymentMethodCard{
+			Number:   p.Card.Number,
+			ExpYear:  p.Card.ExpYear,
+			ExpMonth: p.Card.ExpMonth,
+		}
+	}
+	if p.PayPay != nil {
+		method.PayPay = &domain.PaymentMethodPayPay{AuthorizationURL: p.PayPay.AuthorizationURL}
+	}
+	return method
+}
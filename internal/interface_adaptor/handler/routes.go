@@ -0,0 +1,52 @@
+package handler
+
+import "net/http"
+
+type Route struct {
+	Method string
+	Path   string
+	// Pattern is the net/http ServeMux pattern, "<Method> <Path>".
+	Pattern string
+	Handle  http.HandlerFunc
+	// authenticate runs on the raw body before schema validation, so unauthenticated callers never learn the schema.
+	authenticate requestAuthenticator
+}
+
+// requestAuthenticator rejects a request before its body is validated or decoded.
+type requestAuthenticator func(r *http.Request, body []byte) error
+
+// Routes lists the registered endpoints without their handlers.
+func Routes() []Route {
+	routes := (&handler{}).routes()
+	for i := range routes {
+		routes[i].Handle = nil
+		routes[i].authenticate = nil
+	}
+	return routes
+}
+
+func (h *handler) routes() []Route {
+	return []Route{
+		newRoute(http.MethodGet, "/openapi.yaml", h.getOpenAPISpec),
+		newRoute(http.MethodPost, "/businesses", h.createBusiness),
+		newRoute(http.MethodPost, "/businesses/{businessID}/carts", h.createCart).authenticatedBy(h.authenticateOperator),
+		newRoute(http.MethodPost, "/businesses/{businessID}/carts/{cartID}/confirm", h.confirmCart).authenticatedBy(h.authenticateOperator),
+		newRoute(http.MethodPost, "/payment_intents", h.initializePaymentIntent),
+		newRoute(http.MethodGet, "/payment_intents/{paymentIntentID}", h.getPaymentIntent),
+		newRoute(http.MethodPost, "/payment_intents/{paymentIntentID}/select", h.selectPaymentMethod),
+		newRoute(http.MethodPost, "/payment_intents/{paymentIntentID}/provide", h.providePaymentMethod),
+		newRoute(http.MethodPost, "/payment_intents/{paymentIntentID}/confirm", h.confirmPaymentIntent),
+		newRoute(http.MethodPost, "/payment_intents/{paymentIntentID}/capture", h.capturePaymentIntent),
+		newRoute(http.MethodPost, "/webhooks/provider", h.receiveProviderEvent).authenticatedBy(h.verifyWebhookSignature),
+		newRoute(http.MethodGet, "/provider_events/orphaned", h.listOrphanedProviderEvents).authenticatedBy(h.authenticateOperator),
+	}
+}
+
+func newRoute(method, path string, handle http.HandlerFunc) Route {
+	return Route{Method: method, Path: path, Pattern: method + " " + path, Handle: handle}
+}
+
+func (r Route) authenticatedBy(authenticate requestAuthenticator) Route {
+	r.authenticate = authenticate
+	return r
+}
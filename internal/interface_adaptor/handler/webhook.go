@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/usecase"
+)
+
+const WebhookSignatureHeader = "Webhook-Signature"
+
+// providerEventIgnored answers event types this service does not handle; the other statuses are the use case results.
+const providerEventIgnored = "ignored"
+
+type (
+	Webhook struct {
+		Verifier service.WebhookVerifier
+	}
+
+	// Operator guards the endpoints meant for operators only: creating and confirming carts, whose prices are taken as
+	// given, and listing orphaned provider events. Requests carry "Authorization: Bearer <Token>".
+	Operator struct {
+		Token string
+	}
+
+	// providerEventPayload is the provider's wire format. Unknown fields are ignored because the provider adds them
+	// without notice.
+	providerEventPayload struct {
+		ID      string `json:"id"`
+		Type    string `json:"type"`
+		Created int64  `json:"created"`
+		Data    struct {
+			PaymentIntentID string `json:"payment_intent_id"`
+			RefundID        string `json:"refund_id"`
+			Failure         struct {
+				Code            string `json:"code"`
+				Category        string `json:"category"`
+				Retryable       bool   `json:"retryable"`
+				CustomerMessage string `json:"customer_message"`
+			} `json:"failure"`
+		} `json:"data"`
+	}
+
+	providerEventResponse struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+
+	pendingProviderEventResponse struct {
+		ID              string    `json:"id"`
+		Type            string    `json:"type"`
+		PaymentIntentID string    `json:"payment_intent_id"`
+		RefundID        string    `json:"refund_id,omitempty"`
+		OccurredAt      time.Time `json:"occurred_at"`
+		ParkedAt        time.Time `json:"parked_at"`
+	}
+
+	pendingProviderEventsResponse struct {
+		Events []pendingProviderEventResponse `json:"events"`
+	}
+)
+
+func (p providerEventPayload) toDomain() domain.ProviderEvent {
+	event := domain.ProviderEvent{
+		ID:              domain.ProviderEventID(p.ID),
+		Type:            domain.ProviderEventType(p.Type),
+		PaymentIntentID: domain.PaymentIntentID(p.Data.PaymentIntentID),
+		RefundID:        domain.PaymentRefundID(p.Data.RefundID),
+		Decline: domain.PaymentDecline{
+			Code:            p.Data.Failure.Code,
+			Category:        domain.PaymentDeclineCategory(p.Data.Failure.Category),
+			Retryable:       p.Data.Failure.Retryable,
+			CustomerMessage: p.Data.Failure.CustomerMessage,
+		},
+	}
+	// 未知の分類で再送を繰り返させないよう、分類なしの失敗として扱う
+	if event.Decline.Category.Validate() != nil {
+		event.Decline.Category = ""
+	}
+	if p.Created > 0 {
+		event.OccurredAt = time.Unix(p.Created, 0).UTC()
+	}
+	return event
+}
+
+// verifyWebhookSignature authenticates the provider before the body is validated against the schema.
+func (h *handler) verifyWebhookSignature(r *http.Request, body []byte) error {
+	return h.webhook.Verifier.Verify(r.Header.Get(WebhookSignatureHeader), body)
+}
+
+// authenticateOperator compares in constant time so that the token cannot be guessed byte by byte.
+func (h *handler) authenticateOperator(r *http.Request, _ []byte) error {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.operator.Token)) != 1 {
+		return fmt.Errorf("%w: operator token is missing or wrong", errUnauthorized)
+	}
+	return nil
+}
+
+// receiveProviderEvent runs after the signature has been verified. It acknowledges with 2xx whenever retrying cannot
+// help: duplicates, events parked until their PaymentIntent catches up and event types this service does not handle.
+// Anything else that fails is left for the provider to redeliver.
+func (h *handler) receiveProviderEvent(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("%w: %v", domain.ErrInvalidArgument, err), nil)
+		return
+	}
+	var payload providerEventPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, r, fmt.Errorf("%w: malformed provider event: %v", domain.ErrInvalidArgument, err), nil)
+		return
+	}
+	event := payload.toDomain()
+	if event.Type.Validate() != nil {
+		writeJSON(w, http.StatusOK, providerEventResponse{ID: payload.ID, Status: providerEventIgnored})
+		return
+	}
+
+	output, err := h.useCases.ApplyPaymentIntentEvent.Execute(r.Context(), usecase.ApplyPaymentIntentEventUseCaseInput{Event: event})
+	if err != nil {
+		var intent domain.PaymentIntent
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+		writeError(w, r, err, intent)
+		return
+	}
+	writeJSON(w, http.StatusOK, providerEventResponse{ID: string(output.EventID), Status: string(output.Result)})
+}
+
+// listOrphanedProviderEvents shows operators the parked events that outlived the inbox TTL. They are replayed only if
+// the provider redelivers them.
+func (h *handler) listOrphanedProviderEvents(w http.ResponseWriter, r *http.Request) {
+	output, err := h.useCases.ListOrphanedProviderEvents.Execute(r.Context(), usecase.ListOrphanedProviderEventsUseCaseInput{})
+	if err != nil {
+		writeError(w, r, err, nil)
+		return
+	}
+
+	response := pendingProviderEventsResponse{Events: make([]pendingProviderEventResponse, 0, len(output.Events))}
+	for _, pending := range output.Events {
+		response.Events = append(response.Events, pendingProviderEventResponse{
+			ID:              string(pending.Event.ID),
+			Type:            string(pending.Event.Type),
+			PaymentIntentID: string(pending.Event.PaymentIntentID),
+			RefundID:        string(pending.Event.RefundID),
+			OccurredAt:      pending.Event.OccurredAt,
+			ParkedAt:        pending.ParkedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
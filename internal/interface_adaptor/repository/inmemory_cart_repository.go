@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+type InMemoryCartRepository struct {
+	mu    sync.RWMutex
+	carts map[domain.CartID]domain.Cart
+}
+
+func NewInMemoryCartRepository() *InMemoryCartRepository {
+	return &InMemoryCartRepository{
+		carts: make(map[domain.CartID]domain.Cart),
+	}
+}
+
+func (i *InMemoryCartRepository) FindBy(ctx context.Context, id domain.CartID) (*domain.Cart, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	cart, ok := i.carts[id]
+	if !ok {
+		return nil, nil
+	}
+	cart.Items = slices.Clone(cart.Items)
+	return &cart, nil
+}
+
+func (i *InMemoryCartRepository) Save(ctx context.Context, cart domain.Cart) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.carts[cart.CartID]; ok {
+		return fmt.Errorf("%w: cart %s already stored", domain.ErrConflict, cart.CartID)
+	}
+	cart.Items = slices.Clone(cart.Items)
+	i.carts[cart.CartID] = cart
+	return nil
+}
@@ -2,11 +2,26 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/converter"
+	repo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+const (
+	defaultPaymentIntentListLimit = 20
+	maxPaymentIntentListLimit     = 100
 )
 
 type InMemoryPaymentIntentRepository struct {
+	mu    sync.RWMutex
 	store *InMemoryEventStore[domain.PaymentIntent, domain.PaymentIntentEvent]
 }
 
@@ -17,23 +32,217 @@ func NewInMemoryPaymentIntentRepository() *InMemoryPaymentIntentRepository {
 }
 
 func (i *InMemoryPaymentIntentRepository) FindBy(ctx context.Context, aggregateID domain.PaymentIntentID) (*domain.PaymentIntent, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.findBy(aggregateID), nil
+}
+
+func (i *InMemoryPaymentIntentRepository) findBy(aggregateID domain.PaymentIntentID) *domain.PaymentIntent {
 	for idx := len(i.store.Entities) - 1; idx >= 0; idx-- {
 		entity := i.store.Entities[idx]
 		if paymentIntentID(entity) == aggregateID {
-			return &entity, nil
+			return &entity
 		}
 	}
-	return nil, nil
+	return nil
 }
 
+// Save rejects aggregates whose SeqNr does not directly follow the stored one so that concurrent writers cannot overwrite each other.
+// It also rejects a new intent for a cart that already has an active one, which a check before Save cannot guarantee.
 func (i *InMemoryPaymentIntentRepository) Save(ctx context.Context, event domain.PaymentIntentEvent, aggregate domain.PaymentIntent) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	next, err := converter.ToPaymentIntentView(aggregate)
+	if err != nil {
+		return err
+	}
+
+	expectedSeqNr := uint64(1)
+	if latest := i.findBy(next.ID); latest != nil {
+		current, err := converter.ToPaymentIntentView(*latest)
+		if err != nil {
+			return err
+		}
+		expectedSeqNr = current.SeqNr + 1
+	}
+	if next.SeqNr != expectedSeqNr {
+		return fmt.Errorf("%w: payment intent %s", repo.ErrConcurrentModification, next.ID)
+	}
+	if expectedSeqNr == 1 {
+		active, err := i.hasActiveIntentFor(next.CartID)
+		if err != nil {
+			return err
+		}
+		if active {
+			return fmt.Errorf("%w: cart %s", repo.ErrActivePaymentIntentExists, next.CartID)
+		}
+	}
+
 	i.store.Events = append(i.store.Events, event)
 	i.store.Entities = append(i.store.Entities, aggregate)
 	return nil
 }
 
+func (i *InMemoryPaymentIntentRepository) hasActiveIntentFor(cartID domain.CartID) (bool, error) {
+	seen := make(map[domain.PaymentIntentID]struct{})
+	for idx := len(i.store.Entities) - 1; idx >= 0; idx-- {
+		entity := i.store.Entities[idx]
+		id := paymentIntentID(entity)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		view, err := converter.ToPaymentIntentView(entity)
+		if err != nil {
+			return false, err
+		}
+		if view.CartID == cartID && slices.Contains(domain.ActivePaymentIntentStatuses(), entity.Status()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (i *InMemoryPaymentIntentRepository) Events() []domain.PaymentIntentEvent {
-	return i.store.Events
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return slices.Clone(i.store.Events)
+}
+
+func (i *InMemoryPaymentIntentRepository) List(ctx context.Context, query repo.PaymentIntentQuery) (repo.PaymentIntentPage, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultPaymentIntentListLimit
+	}
+	if limit > maxPaymentIntentListLimit {
+		limit = maxPaymentIntentListLimit
+	}
+
+	var after *paymentIntentCursor
+	if query.Cursor != "" {
+		cursor, err := decodePaymentIntentCursor(query.Cursor)
+		if err != nil {
+			return repo.PaymentIntentPage{}, err
+		}
+		after = &cursor
+	}
+
+	candidates := make([]domain.PaymentIntent, 0)
+	views := make(map[domain.PaymentIntentID]converter.PaymentIntentView)
+	for idx := len(i.store.Entities) - 1; idx >= 0; idx-- {
+		entity := i.store.Entities[idx]
+		id := paymentIntentID(entity)
+		if _, seen := views[id]; seen {
+			continue
+		}
+		view, err := converter.ToPaymentIntentView(entity)
+		if err != nil {
+			return repo.PaymentIntentPage{}, err
+		}
+		views[id] = view
+		if matchesPaymentIntentQuery(view, query) {
+			candidates = append(candidates, entity)
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b domain.PaymentIntent) int {
+		return compareCursor(cursorOf(views[paymentIntentID(a)]), cursorOf(views[paymentIntentID(b)]))
+	})
+
+	if after != nil {
+		start := len(candidates)
+		for idx, candidate := range candidates {
+			if compareCursor(cursorOf(views[paymentIntentID(candidate)]), *after) > 0 {
+				start = idx
+				break
+			}
+		}
+		candidates = candidates[start:]
+	}
+
+	page := repo.PaymentIntentPage{}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+		page.NextCursor = encodePaymentIntentCursor(cursorOf(views[paymentIntentID(candidates[limit-1])]))
+	}
+	page.PaymentIntents = candidates
+
+	return page, nil
+}
+
+func matchesPaymentIntentQuery(view converter.PaymentIntentView, query repo.PaymentIntentQuery) bool {
+	if query.BusinessID != "" && view.BusinessID != query.BusinessID {
+		return false
+	}
+	if query.CartID != "" && view.CartID != query.CartID {
+		return false
+	}
+	if len(query.Statuses) > 0 && !slices.Contains(query.Statuses, domain.PaymentIntentStatus(view.Status)) {
+		return false
+	}
+	if query.PaymentMethodType != "" && paymentMethodTypeOf(view) != query.PaymentMethodType {
+		return false
+	}
+	if !query.CreatedFrom.IsZero() && view.CreatedAt.Before(query.CreatedFrom) {
+		return false
+	}
+	if !query.CreatedTo.IsZero() && !view.CreatedAt.Before(query.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+func paymentMethodTypeOf(view converter.PaymentIntentView) domain.PaymentMethodType {
+	if view.PaymentMethodType != "" {
+		return view.PaymentMethodType
+	}
+	return view.PaymentMethod.PaymentMethodType
+}
+
+type paymentIntentCursor struct {
+	createdAt time.Time
+	id        domain.PaymentIntentID
+}
+
+func cursorOf(view converter.PaymentIntentView) paymentIntentCursor {
+	return paymentIntentCursor{createdAt: view.CreatedAt, id: view.ID}
+}
+
+func compareCursor(a, b paymentIntentCursor) int {
+	if c := a.createdAt.Compare(b.createdAt); c != 0 {
+		return c
+	}
+	return strings.Compare(string(a.id), string(b.id))
+}
+
+func encodePaymentIntentCursor(cursor paymentIntentCursor) repo.PaymentIntentCursor {
+	raw := fmt.Sprintf("%d|%s", cursor.createdAt.UnixNano(), cursor.id)
+	return repo.PaymentIntentCursor(base64.RawURLEncoding.EncodeToString([]byte(raw)))
+}
+
+func decodePaymentIntentCursor(cursor repo.PaymentIntentCursor) (paymentIntentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return paymentIntentCursor{}, fmt.Errorf("%w: invalid payment intent cursor", domain.ErrInvalidArgument)
+	}
+	nanos, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return paymentIntentCursor{}, fmt.Errorf("%w: invalid payment intent cursor", domain.ErrInvalidArgument)
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return paymentIntentCursor{}, fmt.Errorf("%w: invalid payment intent cursor", domain.ErrInvalidArgument)
+	}
+	return paymentIntentCursor{
+		createdAt: time.Unix(0, unixNano),
+		id:        domain.PaymentIntentID(id),
+	}, nil
 }
 
 func paymentIntentID(intent domain.PaymentIntent) domain.PaymentIntentID {
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+type InMemoryPendingProviderEventRepository struct {
+	mu     sync.RWMutex
+	events map[domain.ProviderEventID]domain.PendingProviderEvent
+}
+
+func NewInMemoryPendingProviderEventRepository() *InMemoryPendingProviderEventRepository {
+	return &InMemoryPendingProviderEventRepository{
+		events: make(map[domain.ProviderEventID]domain.PendingProviderEvent),
+	}
+}
+
+func (i *InMemoryPendingProviderEventRepository) Exists(ctx context.Context, id domain.ProviderEventID) (bool, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	_, ok := i.events[id]
+	return ok, nil
+}
+
+func (i *InMemoryPendingProviderEventRepository) Save(ctx context.Context, pending domain.PendingProviderEvent) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.events[pending.Event.ID]; ok {
+		return fmt.Errorf("%w: provider event %s already parked", domain.ErrConflict, pending.Event.ID)
+	}
+	i.events[pending.Event.ID] = pending
+	return nil
+}
+
+func (i *InMemoryPendingProviderEventRepository) FindByPaymentIntentID(ctx context.Context, id domain.PaymentIntentID) ([]domain.PendingProviderEvent, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var events []domain.PendingProviderEvent
+	for _, pending := range i.events {
+		if pending.Event.PaymentIntentID == id {
+			events = append(events, pending)
+		}
+	}
+	slices.SortFunc(events, func(a, b domain.PendingProviderEvent) int {
+		if c := a.Event.OccurredAt.Compare(b.Event.OccurredAt); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Event.ID), string(b.Event.ID))
+	})
+	return events, nil
+}
+
+func (i *InMemoryPendingProviderEventRepository) List(ctx context.Context) ([]domain.PendingProviderEvent, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	events := make([]domain.PendingProviderEvent, 0, len(i.events))
+	for _, pending := range i.events {
+		events = append(events, pending)
+	}
+	slices.SortFunc(events, func(a, b domain.PendingProviderEvent) int {
+		if c := a.ParkedAt.Compare(b.ParkedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Event.ID), string(b.Event.ID))
+	})
+	return events, nil
+}
+
+func (i *InMemoryPendingProviderEventRepository) Delete(ctx context.Context, id domain.ProviderEventID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.events, id)
+	return nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+type InMemoryProviderEventRepository struct {
+	mu     sync.RWMutex
+	events map[domain.ProviderEventID]domain.ProviderEvent
+}
+
+func NewInMemoryProviderEventRepository() *InMemoryProviderEventRepository {
+	return &InMemoryProviderEventRepository{
+		events: make(map[domain.ProviderEventID]domain.ProviderEvent),
+	}
+}
+
+func (i *InMemoryProviderEventRepository) Exists(ctx context.Context, id domain.ProviderEventID) (bool, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	_, ok := i.events[id]
+	return ok, nil
+}
+
+func (i *InMemoryProviderEventRepository) Save(ctx context.Context, event domain.ProviderEvent) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.events[event.ID]; ok {
+		return fmt.Errorf("%w: provider event %s already recorded", domain.ErrConflict, event.ID)
+	}
+	i.events[event.ID] = event
+	return nil
+}
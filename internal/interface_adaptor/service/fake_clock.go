@@ -0,0 +1,21 @@
+package service
+
+import (
+	"time"
+)
+
+type FakeClock struct {
+	Current time.Time
+}
+
+func NewFakeClock(current time.Time) *FakeClock {
+	return &FakeClock{Current: current}
+}
+
+func (f *FakeClock) Now() time.Time {
+	return f.Current
+}
+
+func (f *FakeClock) Advance(d time.Duration) {
+	f.Current = f.Current.Add(d)
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type FakeRefundIDGenerator struct {
+	NextID domain.PaymentRefundID
+	Err    error
+}
+
+func NewFakeRefundIDGenerator(nextID domain.PaymentRefundID) service.RefundIDGenerator {
+	return &FakeRefundIDGenerator{NextID: nextID}
+}
+
+func (f *FakeRefundIDGenerator) GenerateID(ctx context.Context) (domain.PaymentRefundID, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	return f.NextID, nil
+}
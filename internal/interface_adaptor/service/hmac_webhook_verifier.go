@@ -0,0 +1,90 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+const DefaultWebhookTolerance = 5 * time.Minute
+
+// HMACWebhookVerifier checks signatures of the form "t=<unix seconds>,v1=<hex HMAC-SHA256>" where the MAC covers
+// "<t>.<payload>". Several v1 entries are accepted so that the provider can rotate secrets.
+type HMACWebhookVerifier struct {
+	secret    []byte
+	tolerance time.Duration
+	clock     service.Clock
+}
+
+func NewHMACWebhookVerifier(secret []byte, tolerance time.Duration, clock service.Clock) *HMACWebhookVerifier {
+	if len(secret) == 0 {
+		panic("secret is empty")
+	}
+	if tolerance <= 0 {
+		panic("tolerance must be positive")
+	}
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &HMACWebhookVerifier{secret: secret, tolerance: tolerance, clock: clock}
+}
+
+func (v *HMACWebhookVerifier) Verify(signature string, payload []byte) error {
+	var timestamp string
+	var macs [][]byte
+	for _, part := range strings.Split(signature, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			mac, err := hex.DecodeString(value)
+			if err == nil {
+				macs = append(macs, mac)
+			}
+		}
+	}
+	if timestamp == "" || len(macs) == 0 {
+		return fmt.Errorf("%w: malformed signature header", service.ErrInvalidWebhookSignature)
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp", service.ErrInvalidWebhookSignature)
+	}
+	// 古い署名の再送と、時計が大きくずれた送信元の両方を拒否する
+	if skew := v.clock.Now().Sub(time.Unix(seconds, 0)); skew > v.tolerance || skew < -v.tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", service.ErrInvalidWebhookSignature)
+	}
+
+	expected := v.mac(timestamp, payload)
+	for _, mac := range macs {
+		if hmac.Equal(mac, expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature mismatch", service.ErrInvalidWebhookSignature)
+}
+
+// Sign builds the header value the provider would send for payload at the given time.
+func (v *HMACWebhookVerifier) Sign(payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(v.mac(timestamp, payload))
+}
+
+func (v *HMACWebhookVerifier) mac(timestamp string, payload []byte) []byte {
+	h := hmac.New(sha256.New, v.secret)
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	// PaymentMethodProviders maps each payment method type to the provider that processes it.
+	PaymentMethodProviders map[domain.PaymentMethodType]service.PaymentMethodProviderService
+
+	paymentMethodProviderRegistry struct {
+		defaults  PaymentMethodProviders
+		overrides map[domain.BusinessID]PaymentMethodProviders
+	}
+)
+
+// NewPaymentMethodProviderRegistry routes by payment method type. A business override only replaces the types it
+// lists; the other types fall back to defaults.
+func NewPaymentMethodProviderRegistry(
+	defaults PaymentMethodProviders,
+	overrides map[domain.BusinessID]PaymentMethodProviders,
+) service.PaymentMethodProviderRegistry {
+	registry := &paymentMethodProviderRegistry{
+		defaults:  clonePaymentMethodProviders(defaults),
+		overrides: make(map[domain.BusinessID]PaymentMethodProviders, len(overrides)),
+	}
+	for businessID, providers := range overrides {
+		if err := businessID.Validate(); err != nil {
+			panic(err)
+		}
+		registry.overrides[businessID] = clonePaymentMethodProviders(providers)
+	}
+	return registry
+}
+
+func (r *paymentMethodProviderRegistry) ProviderFor(businessID domain.BusinessID, paymentMethodType domain.PaymentMethodType) (service.PaymentMethodProviderService, error) {
+	if provider, ok := r.overrides[businessID][paymentMethodType]; ok {
+		return provider, nil
+	}
+	if provider, ok := r.defaults[paymentMethodType]; ok {
+		return provider, nil
+	}
+	return nil, fmt.Errorf("%w: payment method type %q for business %s", service.ErrPaymentMethodProviderNotFound, paymentMethodType, businessID)
+}
+
+func clonePaymentMethodProviders(providers PaymentMethodProviders) PaymentMethodProviders {
+	for paymentMethodType, provider := range providers {
+		if err := paymentMethodType.Validate(); err != nil {
+			panic(err)
+		}
+		if provider == nil {
+			panic("payment method provider is nil")
+		}
+	}
+	return maps.Clone(providers)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+func TestPaymentMethodProviderRegistry_ShouldRouteByTypeWithBusinessOverrides(t *testing.T) {
+	card := NewPaymentMethodProviderService(domain.PaymentConfirmationNextProcessing)
+	payPay := NewPaymentMethodProviderService(domain.PaymentConfirmationNextRequiresAction)
+	dedicatedCard := NewPaymentMethodProviderService(domain.PaymentConfirmationNextRequiresCapture)
+
+	registry := NewPaymentMethodProviderRegistry(
+		PaymentMethodProviders{
+			domain.PaymentMethodTypeCard:   card,
+			domain.PaymentMethodTypePayPay: payPay,
+		},
+		map[domain.BusinessID]PaymentMethodProviders{
+			"biz_dedicated": {domain.PaymentMethodTypeCard: dedicatedCard},
+		},
+	)
+
+	tests := []struct {
+		name              string
+		businessID        domain.BusinessID
+		paymentMethodType domain.PaymentMethodType
+		want              service.PaymentMethodProviderService
+	}{
+		{name: "default card", businessID: "biz_1", paymentMethodType: domain.PaymentMethodTypeCard, want: card},
+		{name: "default paypay", businessID: "biz_1", paymentMethodType: domain.PaymentMethodTypePayPay, want: payPay},
+		{name: "overridden card", businessID: "biz_dedicated", paymentMethodType: domain.PaymentMethodTypeCard, want: dedicatedCard},
+		{name: "paypay falls back to default", businessID: "biz_dedicated", paymentMethodType: domain.PaymentMethodTypePayPay, want: payPay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := registry.ProviderFor(tt.businessID, tt.paymentMethodType)
+			require.NoError(t, err)
+			assert.Same(t, tt.want, provider)
+		})
+	}
+}
+
+func TestPaymentMethodProviderRegistry_ShouldRejectUnroutedType(t *testing.T) {
+	registry := NewPaymentMethodProviderRegistry(PaymentMethodProviders{
+		domain.PaymentMethodTypeCard: NewPaymentMethodProviderService(""),
+	}, nil)
+
+	_, err := registry.ProviderFor("biz_1", domain.PaymentMethodTypePayPay)
+	require.ErrorIs(t, err, service.ErrPaymentMethodProviderNotFound)
+	assert.Contains(t, err.Error(), "paypay")
+}
+
+func TestPaymentMethodProviderRegistry_ShouldPanicOnInvalidRoutes(t *testing.T) {
+	assert.Panics(t, func() {
+		NewPaymentMethodProviderRegistry(PaymentMethodProviders{domain.PaymentMethodTypeCard: nil}, nil)
+	})
+	assert.Panics(t, func() {
+		NewPaymentMethodProviderRegistry(PaymentMethodProviders{"": NewPaymentMethodProviderService("")}, nil)
+	})
+}
@@ -25,3 +25,15 @@ func (p *paymentMethodProviderServiceImpl) ConfirmPaymentMethod(ctx context.Cont
 func (p *paymentMethodProviderServiceImpl) CapturePaymentIntent(ctx context.Context, request service.PaymentCaptureRequest) error {
 	return nil
 }
+
+func (p *paymentMethodProviderServiceImpl) VoidAuthorization(ctx context.Context, request service.PaymentVoidRequest) error {
+	return nil
+}
+
+func (p *paymentMethodProviderServiceImpl) IncrementAuthorization(ctx context.Context, request service.PaymentIncrementAuthorizationRequest) error {
+	return nil
+}
+
+func (p *paymentMethodProviderServiceImpl) RefundPayment(ctx context.Context, request service.PaymentRefundRequest) error {
+	return nil
+}
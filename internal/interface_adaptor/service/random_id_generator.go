@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+// RandomIDGenerator issues prefixed IDs backed by crypto/rand, e.g. "pi_3f9c...".
+type RandomIDGenerator[ID ~string] struct {
+	Prefix string
+}
+
+func NewRandomBusinessIDGenerator() service.BusinessIDGenerator {
+	return &RandomIDGenerator[domain.BusinessID]{Prefix: "biz_"}
+}
+
+func NewRandomCartIDGenerator() service.CartIDGenerator {
+	return &RandomIDGenerator[domain.CartID]{Prefix: "cart_"}
+}
+
+func NewRandomPaymentIntentIDGenerator() service.PaymentIDGenerator {
+	return &RandomIDGenerator[domain.PaymentIntentID]{Prefix: "pi_"}
+}
+
+func NewRandomRefundIDGenerator() service.RefundIDGenerator {
+	return &RandomIDGenerator[domain.PaymentRefundID]{Prefix: "re_"}
+}
+
+func (r *RandomIDGenerator[ID]) GenerateID(ctx context.Context) (ID, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return ID(r.Prefix + hex.EncodeToString(buf)), nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"time"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type systemClock struct{}
+
+func NewSystemClock() service.Clock {
+	return &systemClock{}
+}
+
+func (s *systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -1,19 +1,33 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"io"
 	"strings"
+	"unicode/utf8"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
 )
 
-type tokenServiceImpl struct{}
+type tokenServiceImpl struct {
+	secret []byte
+}
 
-func NewTokenService() service.TokenService {
-	return &tokenServiceImpl{}
+// NewTokenService signs cart tokens with secret, so that a client cannot change the items or prices it was quoted.
+func NewTokenService(secret []byte) service.TokenService {
+	if len(secret) == 0 {
+		panic("secret is empty")
+	}
+	return &tokenServiceImpl{secret: secret}
 }
 
 func (s *tokenServiceImpl) IssuePaymentToken(ctx context.Context, input service.IssuePaymentTokenInput) (service.SignedToken, error) {
@@ -27,70 +41,118 @@ func (s *tokenServiceImpl) RelayTokens(ctx context.Context, input service.RelayT
 	}, nil
 }
 
+type (
+	// cartTokenPayload is the body of a cart token. It is carried as base64url JSON so that IDs may contain any character,
+	// followed by "." and the base64url HMAC-SHA256 of the encoded body.
+	cartTokenPayload struct {
+		BusinessID string          `json:"business_id"`
+		CartID     string          `json:"cart_id"`
+		Items      []cartTokenItem `json:"items"`
+	}
+
+	cartTokenItem struct {
+		ItemID string `json:"item_id"`
+		Price  uint64 `json:"price"`
+	}
+)
+
+const (
+	cartTokenPrefix = "cart-token:"
+	// 任意の入力を受けるので、デコード前に大きさを制限する
+	maxCartTokenLength = 64 << 10
+)
+
 func (s *tokenServiceImpl) ConfirmCartToken(ctx context.Context, input service.ConfirmCartTokenInput) (service.SignedToken, error) {
-	items := make([]string, len(input.Cart.Items))
+	if err := contract.Validate(input.Cart); err != nil {
+		return service.SignedToken{}, err
+	}
+
+	payload := cartTokenPayload{
+		BusinessID: string(input.Cart.BusinessID),
+		CartID:     string(input.Cart.CartID),
+		Items:      make([]cartTokenItem, len(input.Cart.Items)),
+	}
 	for i, item := range input.Cart.Items {
-		items[i] = fmt.Sprintf("%s=%d", item.ItemID, item.Price)
-	}
-	return service.SignedToken{
-		Value: fmt.Sprintf(
-			"cart-token:%s:%s:%s",
-			input.Cart.BusinessID,
-			input.Cart.CartID,
-			strings.Join(items, "|"),
-		),
-	}, nil
+		payload.Items[i] = cartTokenItem{ItemID: string(item.ItemID), Price: uint64(item.Price)}
+	}
+	// JSON は不正な UTF-8 を置き換えてしまい、往復で値が変わるため受け付けない
+	if !utf8.ValidString(payload.BusinessID) || !utf8.ValidString(payload.CartID) {
+		return service.SignedToken{}, fmt.Errorf("%w: cart ids must be valid utf-8", domain.ErrInvalidArgument)
+	}
+	for _, item := range payload.Items {
+		if !utf8.ValidString(item.ItemID) {
+			return service.SignedToken{}, fmt.Errorf("%w: item ids must be valid utf-8", domain.ErrInvalidArgument)
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return service.SignedToken{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	value := cartTokenPrefix + encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded))
+	if len(value) > maxCartTokenLength {
+		return service.SignedToken{}, fmt.Errorf("%w: cart token is too large", domain.ErrInvalidArgument)
+	}
+	return service.SignedToken{Value: value}, nil
 }
 
 func (s *tokenServiceImpl) ParseCartToken(ctx context.Context, token service.SignedToken) (domain.Cart, error) {
-	if len(token.Value) == 0 {
-		return domain.Cart{}, fmt.Errorf("cart token is empty")
+	if err := contract.Validate(token); err != nil {
+		return domain.Cart{}, err
 	}
-	if !strings.HasPrefix(token.Value, "cart-token:") {
-		return domain.Cart{}, fmt.Errorf("invalid cart token")
+	if len(token.Value) > maxCartTokenLength {
+		return domain.Cart{}, fmt.Errorf("%w: cart token is too large", domain.ErrInvalidArgument)
 	}
-
-	parts := strings.SplitN(token.Value, ":", 4)
-	if len(parts) != 4 {
-		return domain.Cart{}, fmt.Errorf("invalid cart token format")
+	signed, ok := strings.CutPrefix(token.Value, cartTokenPrefix)
+	if !ok {
+		return domain.Cart{}, fmt.Errorf("%w: invalid cart token", domain.ErrInvalidArgument)
 	}
-
-	businessID := domain.BusinessID(parts[1])
-	cartID := domain.CartID(parts[2])
-	itemPart := parts[3]
-	if len(itemPart) == 0 {
-		return domain.Cart{}, fmt.Errorf("invalid cart token: empty items")
+	encoded, signature, ok := strings.Cut(signed, ".")
+	if !ok {
+		return domain.Cart{}, fmt.Errorf("%w: cart token is not signed", domain.ErrInvalidArgument)
+	}
+	// 中身を読む前に署名を確かめ、クライアントが書き換えた金額を受け付けない
+	mac, err := base64.RawURLEncoding.Strict().DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(encoded)) {
+		return domain.Cart{}, fmt.Errorf("%w: invalid cart token signature", domain.ErrInvalidArgument)
 	}
 
-	itemStrings := strings.Split(itemPart, "|")
-	items := make([]domain.CartItem, 0, len(itemStrings))
-	for _, item := range itemStrings {
-		if len(item) == 0 {
-			continue
-		}
+	body, err := base64.RawURLEncoding.Strict().DecodeString(encoded)
+	if err != nil {
+		return domain.Cart{}, fmt.Errorf("%w: invalid cart token encoding", domain.ErrInvalidArgument)
+	}
 
-		parts := strings.SplitN(item, "=", 2)
-		if len(parts) != 2 {
-			return domain.Cart{}, fmt.Errorf("invalid cart item format")
-		}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	var payload cartTokenPayload
+	if err := decoder.Decode(&payload); err != nil {
+		return domain.Cart{}, fmt.Errorf("%w: invalid cart token payload", domain.ErrInvalidArgument)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return domain.Cart{}, fmt.Errorf("%w: trailing data in cart token", domain.ErrInvalidArgument)
+	}
 
-		price, err := strconv.ParseUint(parts[1], 10, 8)
-		if err != nil {
-			return domain.Cart{}, fmt.Errorf("invalid cart item price: %w", err)
+	items := make(domain.CartItems, len(payload.Items))
+	for i, item := range payload.Items {
+		items[i] = domain.CartItem{
+			ItemID: domain.ItemID(item.ItemID),
+			Price:  domain.ItemPrice(item.Price),
 		}
-
-		items = append(items, domain.CartItem{
-			ItemID: domain.ItemID(parts[0]),
-			Price:  domain.ItemPrice(price),
-		})
 	}
-
-	cartItems := domain.CartItems(items)
 	cart := domain.Cart{
-		BusinessID: businessID,
-		CartID:     cartID,
-		Items:      cartItems,
+		BusinessID: domain.BusinessID(payload.BusinessID),
+		CartID:     domain.CartID(payload.CartID),
+		Items:      items,
 	}
+	if err := contract.Validate(cart); err != nil {
+		return domain.Cart{}, err
+	}
+	return cart, nil
+}
 
-	return cart, cart.Validate()
+func (s *tokenServiceImpl) mac(encoded string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(encoded))
+	return h.Sum(nil)
 }
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+var testCartTokenSecret = []byte("cart_token_secret")
+
+func TestTokenService_ShouldRoundTripCartWithSeparatorsAndLargePrices(t *testing.T) {
+	ctx := context.Background()
+	tokenService := NewTokenService(testCartTokenSecret)
+
+	cart := domain.Cart{
+		BusinessID: "biz:1|2=3",
+		CartID:     "cart=|:",
+		Items: domain.CartItems{
+			{ItemID: "item:a|b=c", Price: 1980},
+			{ItemID: "商品", Price: 120},
+		},
+	}
+
+	token, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{Cart: cart})
+	require.NoError(t, err)
+
+	parsed, err := tokenService.ParseCartToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, cart, parsed)
+	assert.Equal(t, domain.Money(2100), parsed.CalculateAmount())
+}
+
+func TestTokenService_ShouldRejectTamperedCartToken(t *testing.T) {
+	ctx := context.Background()
+	tokenService := NewTokenService(testCartTokenSecret)
+
+	token, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{Cart: domain.Cart{
+		BusinessID: "biz_1",
+		CartID:     "cart_1",
+		Items:      domain.CartItems{{ItemID: "item_1", Price: 1980}},
+	}})
+	require.NoError(t, err)
+	encoded, signature, ok := strings.Cut(strings.TrimPrefix(token.Value, cartTokenPrefix), ".")
+	require.True(t, ok)
+
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	discounted := strings.Replace(string(body), `"price":1980`, `"price":1`, 1)
+	require.NotEqual(t, string(body), discounted)
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "price rewritten", value: cartTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(discounted)) + "." + signature},
+		{name: "signature stripped", value: cartTokenPrefix + encoded},
+		{name: "issued with another secret", value: func() string {
+			other, err := NewTokenService([]byte("other_secret")).ConfirmCartToken(ctx, service.ConfirmCartTokenInput{Cart: domain.Cart{
+				BusinessID: "biz_1",
+				CartID:     "cart_1",
+				Items:      domain.CartItems{{ItemID: "item_1", Price: 1}},
+			}})
+			require.NoError(t, err)
+			return other.Value
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tokenService.ParseCartToken(ctx, service.SignedToken{Value: tt.value})
+			assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+		})
+	}
+}
+
+func FuzzTokenService_CartTokenRoundTrip(f *testing.F) {
+	f.Add("biz_123", "cart_123", "item_1", uint64(120), "item_2", uint64(1))
+	f.Add("biz:1", "cart|1", "item=1", uint64(100000), "a:b|c=d", uint64(math.MaxUint32))
+	f.Add("b", "c", "", uint64(0), "i", uint64(math.MaxUint64))
+
+	ctx := context.Background()
+	tokenService := NewTokenService(testCartTokenSecret)
+
+	f.Fuzz(func(t *testing.T, businessID, cartID, itemID1 string, price1 uint64, itemID2 string, price2 uint64) {
+		cart := domain.Cart{
+			BusinessID: domain.BusinessID(businessID),
+			CartID:     domain.CartID(cartID),
+			Items: domain.CartItems{
+				{ItemID: domain.ItemID(itemID1), Price: domain.ItemPrice(price1)},
+				{ItemID: domain.ItemID(itemID2), Price: domain.ItemPrice(price2)},
+			},
+		}
+
+		token, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{Cart: cart})
+		if err != nil {
+			// 発行を拒否するのは不正なカートか、JSON で表せない文字列だけ
+			invalidUTF8 := !utf8.ValidString(businessID) || !utf8.ValidString(cartID) || !utf8.ValidString(itemID1) || !utf8.ValidString(itemID2)
+			if cart.Validate() == nil && !invalidUTF8 {
+				t.Fatalf("valid cart was rejected: %v", err)
+			}
+			assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+			return
+		}
+
+		parsed, err := tokenService.ParseCartToken(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, cart, parsed)
+	})
+}
+
+func FuzzTokenService_ParseCartTokenArbitraryInput(f *testing.F) {
+	f.Add("")
+	f.Add("cart-token:")
+	f.Add("cart-token:biz_123:cart_123:item_1=120")
+	f.Add("cart-token:eyJidXNpbmVzc19pZCI6ImIiLCJjYXJ0X2lkIjoiYyIsIml0ZW1zIjpbeyJpdGVtX2lkIjoiaSIsInByaWNlIjoxfV19")
+	f.Add("cart-token:eyJidXNpbmVzc19pZCI6ImIiLCJjYXJ0X2lkIjoiYyIsIml0ZW1zIjpbXX0")
+	f.Add("cart-token:e30")
+
+	ctx := context.Background()
+	tokenService := NewTokenService(testCartTokenSecret)
+
+	f.Fuzz(func(t *testing.T, value string) {
+		cart, err := tokenService.ParseCartToken(ctx, service.SignedToken{Value: value})
+		if err != nil {
+			assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+			assert.Equal(t, domain.Cart{}, cart)
+			return
+		}
+
+		// 受け付けたトークンは有効なカートを表し、再発行すると同じトークンになる
+		require.NoError(t, cart.Validate())
+		reissued, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{Cart: cart})
+		require.NoError(t, err)
+		reparsed, err := tokenService.ParseCartToken(ctx, reissued)
+		require.NoError(t, err)
+		assert.Equal(t, cart, reparsed)
+	})
+}
@@ -0,0 +1,227 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/handler"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/usecase"
+)
+
+var (
+	testWebhookSecret   = []byte("whsec_test")
+	testCartTokenSecret = []byte("cart_token_secret")
+	testOperatorToken   = "operator_token"
+	testHTTPNow         = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func newTestHTTPHandler(confirmationNext domain.PaymentConfirmationNext) http.Handler {
+	businessRepo := iarepo.NewInMemoryBusinessRepository()
+	paymentIntentRepo := iarepo.NewInMemoryPaymentIntentRepository()
+	cartRepo := iarepo.NewInMemoryCartRepository()
+	tokenService := iasvc.NewTokenService(testCartTokenSecret)
+	// 決済手段ごとに別のアクワイアラを使う。スタブなので中身は同じ
+	paymentProviders := iasvc.NewPaymentMethodProviderRegistry(iasvc.PaymentMethodProviders{
+		domain.PaymentMethodTypeCard:   iasvc.NewPaymentMethodProviderService(confirmationNext),
+		domain.PaymentMethodTypePayPay: iasvc.NewPaymentMethodProviderService(confirmationNext),
+	}, nil)
+	clock := iasvc.NewFakeClock(testHTTPNow)
+	attemptPolicy := domain.PaymentAttemptPolicy{MaxFailedAttempts: 3}
+
+	// 外部通知の適用側は素のリポジトリを使い、こちらから進める側は保存直後に保留中の通知を再生する
+	providerEventRepo := iarepo.NewInMemoryProviderEventRepository()
+	pendingProviderEventRepo := iarepo.NewInMemoryPendingProviderEventRepository()
+	eventHandlers := usecase.PaymentIntentEventHandlers{
+		ActionResult:     usecase.NewHandlePaymentActionResultUseCase(paymentIntentRepo, clock),
+		PaymentSucceeded: usecase.NewHandlePaymentSucceededUseCase(paymentIntentRepo),
+		PaymentFailed:    usecase.NewHandlePaymentFailedUseCase(paymentIntentRepo, attemptPolicy, clock),
+		CaptureFailed:    usecase.NewHandleCaptureFailedUseCase(paymentIntentRepo, clock),
+		RefundSucceeded:  usecase.NewHandleRefundSucceededUseCase(paymentIntentRepo),
+		RefundFailed:     usecase.NewHandleRefundFailedUseCase(paymentIntentRepo),
+	}
+	inboxRepo := usecase.NewPaymentIntentRepositoryWithInbox(
+		paymentIntentRepo,
+		usecase.NewReplayPendingProviderEventsUseCase(providerEventRepo, pendingProviderEventRepo, paymentIntentRepo, eventHandlers, domain.DefaultProviderEventInboxPolicy(), clock),
+	)
+
+	return handler.NewHandler(handler.UseCases{
+		CreateBusiness:          usecase.NewCreateBusinessUseCase(iasvc.NewRandomBusinessIDGenerator(), businessRepo),
+		CreateCart:              usecase.NewCreateCartUseCase(iasvc.NewRandomCartIDGenerator(), cartRepo),
+		ConfirmCart:             usecase.NewConfirmCartUseCase(tokenService, cartRepo),
+		InitializePaymentIntent: usecase.NewInitializePaymentIntentUseCase(tokenService, paymentIntentRepo, iasvc.NewRandomPaymentIntentIDGenerator(), businessRepo, clock),
+		SelectPaymentMethod:     usecase.NewSelectPaymentMethodUseCase(inboxRepo, clock),
+		ProvidePaymentMethod:    usecase.NewProvidePaymentMethodUseCase(inboxRepo),
+		ConfirmPaymentIntent:    usecase.NewConfirmPaymentIntentUseCase(inboxRepo, paymentProviders, attemptPolicy, clock),
+		CapturePaymentIntent:    usecase.NewCapturePaymentIntentUseCase(inboxRepo, paymentProviders, domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, clock),
+		GetPaymentIntent:        usecase.NewGetPaymentIntentUseCase(paymentIntentRepo),
+
+		ApplyPaymentIntentEvent: usecase.NewApplyPaymentIntentEventUseCase(
+			providerEventRepo,
+			pendingProviderEventRepo,
+			paymentIntentRepo,
+			eventHandlers,
+			domain.DefaultProviderEventInboxPolicy(),
+			clock,
+		),
+		ListOrphanedProviderEvents: usecase.NewListOrphanedProviderEventsUseCase(pendingProviderEventRepo, domain.DefaultProviderEventInboxPolicy(), clock),
+	}, handler.Webhook{
+		Verifier: iasvc.NewHMACWebhookVerifier(testWebhookSecret, iasvc.DefaultWebhookTolerance, clock),
+	}, handler.Operator{Token: testOperatorToken})
+}
+
+// doJSON calls h as the operator; the operator token is ignored by endpoints that do not require it.
+func doJSON(t *testing.T, h http.Handler, method, path string, body any) (int, map[string]any) {
+	t.Helper()
+
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		encoded, err := json.Marshal(b)
+		require.NoError(t, err)
+		reader = bytes.NewReader(encoded)
+	}
+
+	request := httptest.NewRequest(method, path, reader)
+	request.Header.Set("Authorization", "Bearer "+testOperatorToken)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, request)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response), recorder.Body.String())
+	return recorder.Code, response
+}
+
+func TestHTTPHandler_ShouldRunCheckoutFlow(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextRequiresCapture)
+
+	status, business := doJSON(t, h, http.MethodPost, "/businesses", map[string]any{
+		"name":                 "shop",
+		"payment_method_types": []string{"card"},
+	})
+	require.Equal(t, http.StatusCreated, status, business)
+	businessID := business["id"].(string)
+
+	items := []map[string]any{{"item_id": "item:1|a=b", "price": 1980}}
+	status, cart := doJSON(t, h, http.MethodPost, "/businesses/"+businessID+"/carts", map[string]any{"items": items})
+	require.Equal(t, http.StatusCreated, status, cart)
+	assert.EqualValues(t, 1980, cart["amount"])
+
+	status, token := doJSON(t, h, http.MethodPost, "/businesses/"+businessID+"/carts/"+cart["cart_id"].(string)+"/confirm", nil)
+	require.Equal(t, http.StatusOK, status, token)
+
+	status, intent := doJSON(t, h, http.MethodPost, "/payment_intents", map[string]any{"cart_token": token["cart_token"]})
+	require.Equal(t, http.StatusCreated, status, intent)
+	assert.Equal(t, "requires_payment_method_type", intent["status"])
+	assert.EqualValues(t, 1980, intent["amount"])
+	path := "/payment_intents/" + intent["id"].(string)
+
+	status, intent = doJSON(t, h, http.MethodPost, path+"/select", map[string]any{"payment_method_type": "card"})
+	require.Equal(t, http.StatusOK, status, intent)
+
+	status, intent = doJSON(t, h, http.MethodPost, path+"/provide", map[string]any{
+		"payment_method": map[string]any{
+			"type": "card",
+			"card": map[string]any{"number": "4242424242424242", "exp_year": 30, "exp_month": 12},
+		},
+		"capture_method": "manual",
+	})
+	require.Equal(t, http.StatusOK, status, intent)
+	assert.Equal(t, "************4242", intent["payment_method"].(map[string]any)["card"].(map[string]any)["number"])
+
+	status, intent = doJSON(t, h, http.MethodPost, path+"/confirm", nil)
+	require.Equal(t, http.StatusOK, status, intent)
+	assert.Equal(t, "requires_capture", intent["status"])
+
+	status, intent = doJSON(t, h, http.MethodPost, path+"/capture", map[string]any{"amount_to_capture": 1000})
+	require.Equal(t, http.StatusOK, status, intent)
+	assert.Equal(t, "processing", intent["status"])
+	assert.EqualValues(t, 1000, intent["amount_captured"])
+
+	status, intent = doJSON(t, h, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, status, intent)
+	assert.Equal(t, "processing", intent["status"])
+}
+
+func TestHTTPHandler_ShouldMapDomainErrorsToStatusCodes(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+
+	status, response := doJSON(t, h, http.MethodPost, "/businesses", `{"name":`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_argument", response["error"].(map[string]any)["code"])
+
+	status, response = doJSON(t, h, http.MethodPost, "/businesses", map[string]any{"name": "shop"})
+	assert.Equal(t, http.StatusBadRequest, status, response)
+
+	status, response = doJSON(t, h, http.MethodGet, "/payment_intents/pi_missing", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "not_found", response["error"].(map[string]any)["code"])
+
+	status, response = doJSON(t, h, http.MethodPost, "/payment_intents", map[string]any{"cart_token": "cart-token:broken"})
+	assert.Equal(t, http.StatusBadRequest, status, response)
+
+	_, business := doJSON(t, h, http.MethodPost, "/businesses", map[string]any{"name": "shop", "payment_method_types": []string{"card"}})
+	token := createCartToken(t, h, business["id"].(string), []map[string]any{{"item_id": "item_1", "price": 120}})
+	_, intent := doJSON(t, h, http.MethodPost, "/payment_intents", map[string]any{"cart_token": token})
+
+	status, response = doJSON(t, h, http.MethodPost, "/payment_intents/"+intent["id"].(string)+"/confirm", nil)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "invalid_state_transition", response["error"].(map[string]any)["code"])
+
+	status, response = doJSON(t, h, http.MethodPost, "/payment_intents", map[string]any{"cart_token": token})
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "conflict", response["error"].(map[string]any)["code"])
+}
+
+func TestHTTPHandler_ShouldSignOnlyStoredCartsForOperators(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+	_, business := doJSON(t, h, http.MethodPost, "/businesses", map[string]any{"name": "shop", "payment_method_types": []string{"card"}})
+	carts := "/businesses/" + business["id"].(string) + "/carts"
+	items := []map[string]any{{"item_id": "item_1", "price": 120}}
+
+	// 運用者トークンがなければカートを作れず、署名もできない
+	for _, path := range []string{carts, carts + "/cart_1/confirm"} {
+		body, err := json.Marshal(map[string]any{"items": items})
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, path)
+	}
+
+	status, response := doJSON(t, h, http.MethodPost, carts+"/cart_missing/confirm", nil)
+	assert.Equal(t, http.StatusNotFound, status, response)
+
+	// 確定時に明細を送っても受け付けない
+	_, cart := doJSON(t, h, http.MethodPost, carts, map[string]any{"items": items})
+	status, response = doJSON(t, h, http.MethodPost, carts+"/"+cart["cart_id"].(string)+"/confirm", map[string]any{"items": []map[string]any{{"item_id": "item_1", "price": 1}}})
+	assert.Equal(t, http.StatusBadRequest, status, response)
+
+	// 別の事業者のカートは確定できない
+	_, other := doJSON(t, h, http.MethodPost, "/businesses", map[string]any{"name": "other", "payment_method_types": []string{"card"}})
+	status, response = doJSON(t, h, http.MethodPost, "/businesses/"+other["id"].(string)+"/carts/"+cart["cart_id"].(string)+"/confirm", nil)
+	assert.Equal(t, http.StatusNotFound, status, response)
+}
+
+func createCartToken(t *testing.T, h http.Handler, businessID string, items []map[string]any) string {
+	t.Helper()
+
+	status, cart := doJSON(t, h, http.MethodPost, "/businesses/"+businessID+"/carts", map[string]any{"items": items})
+	require.Equal(t, http.StatusCreated, status, cart)
+	status, token := doJSON(t, h, http.MethodPost, "/businesses/"+businessID+"/carts/"+cart["cart_id"].(string)+"/confirm", nil)
+	require.Equal(t, http.StatusOK, status, token)
+	return token["cart_token"].(string)
+}
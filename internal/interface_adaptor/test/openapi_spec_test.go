@@ -0,0 +1,102 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/handler"
+)
+
+func TestOpenAPISpec_ShouldDescribeExactlyTheRegisteredRoutes(t *testing.T) {
+	var registered []string
+	for _, route := range handler.Routes() {
+		registered = append(registered, route.Pattern)
+	}
+	sort.Strings(registered)
+
+	described, err := handler.OpenAPIOperations()
+	require.NoError(t, err)
+
+	assert.Equal(t, registered, described)
+}
+
+func TestOpenAPISpec_ShouldResolveEveryRef(t *testing.T) {
+	var document map[string]any
+	require.NoError(t, yaml.Unmarshal(handler.OpenAPISpec(), &document))
+
+	var walk func(at string, node any)
+	walk = func(at string, node any) {
+		switch v := node.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				var target any = document
+				for _, segment := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+					object, ok := target.(map[string]any)
+					require.True(t, ok, "%s: %s does not resolve", at, ref)
+					target, ok = object[segment]
+					require.True(t, ok, "%s: %s does not resolve", at, ref)
+				}
+			}
+			for key, child := range v {
+				walk(at+"/"+key, child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(at, child)
+			}
+		}
+	}
+	walk("#", document)
+}
+
+func TestOpenAPISpec_ShouldRejectRequestsThatDoNotMatchSchema(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		message string
+	}{
+		{name: "missing required", method: http.MethodPost, path: "/businesses", body: `{"name":"shop"}`, message: "body.payment_method_types is required"},
+		{name: "unknown enum", method: http.MethodPost, path: "/businesses", body: `{"name":"shop","payment_method_types":["cash"]}`, message: "body.payment_method_types[0] must be one of card, paypay"},
+		{name: "wrong type", method: http.MethodPost, path: "/businesses/biz_1/carts", body: `{"items":[{"item_id":"i","price":"100"}]}`, message: "body.items[0].price must be an integer"},
+		{name: "integer beyond int64", method: http.MethodPost, path: "/businesses/biz_1/carts", body: `{"items":[{"item_id":"i","price":9223372036854775808}]}`, message: "body.items[0].price is out of range"},
+		{name: "large integer above maximum", method: http.MethodPost, path: "/payment_intents/pi_1/provide", body: `{"payment_method":{"type":"card","card":{"number":"4242","exp_year":30,"exp_month":9007199254740993}},"capture_method":"manual"}`, message: "body.payment_method.card.exp_month must be <= 12"},
+		{name: "fractional integer", method: http.MethodPost, path: "/businesses/biz_1/carts", body: `{"items":[{"item_id":"i","price":1.5}]}`, message: "body.items[0].price must be an integer"},
+		{name: "unknown property", method: http.MethodPost, path: "/payment_intents", body: `{"cart_token":"t","amount":1}`, message: "body.amount is not allowed"},
+		{name: "missing body", method: http.MethodPost, path: "/payment_intents", body: ``, message: "request body is required"},
+		{name: "body not accepted", method: http.MethodPost, path: "/payment_intents/pi_1/confirm", body: `{}`, message: "request body is not accepted"},
+		{name: "out of range", method: http.MethodPost, path: "/payment_intents/pi_1/provide", body: `{"payment_method":{"type":"card","card":{"number":"4242","exp_year":30,"exp_month":13}},"capture_method":"manual"}`, message: "body.payment_method.card.exp_month must be <= 12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := doJSON(t, h, tt.method, tt.path, tt.body)
+			assert.Equal(t, http.StatusBadRequest, status)
+			errorBody := response["error"].(map[string]any)
+			assert.Equal(t, "invalid_argument", errorBody["code"])
+			assert.Contains(t, errorBody["message"], tt.message)
+		})
+	}
+}
+
+func TestOpenAPISpec_ShouldBeServed(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/yaml", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, handler.OpenAPISpec(), recorder.Body.Bytes())
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/converter"
+)
+
+func TestPaymentIntentDiagram_ShouldMatchTransitionTable(t *testing.T) {
+	transitions := domain.PaymentIntentTransitions()
+
+	mermaid := converter.ToPaymentIntentMermaid(transitions)
+	dot := converter.ToPaymentIntentDOT(transitions)
+	for _, transition := range transitions {
+		for _, to := range transition.To {
+			assert.Contains(t, mermaid, fmt.Sprintf("%s --> %s: %s\n", transition.From, to, transition.Action))
+			assert.Contains(t, dot, fmt.Sprintf("%q -> %q [label=%q];\n", transition.From, to, transition.Action))
+		}
+	}
+
+	// docs の図は go run ./cmd/payment-intent-diagram -format mermaid で再生成する
+	committed, err := os.ReadFile(filepath.Join("..", "..", "..", "docs", "payment_intent_state_machine.mmd"))
+	require.NoError(t, err)
+	assert.Equal(t, mermaid, string(committed), "docs/payment_intent_state_machine.mmd is out of date")
+}
+
+func TestPaymentIntentDiagram_ShouldRejectUnknownFormat(t *testing.T) {
+	_, err := converter.ToPaymentIntentDiagram("svg", domain.PaymentIntentTransitions())
+	assert.ErrorIs(t, err, domain.ErrInvalidArgument)
+}
@@ -0,0 +1,282 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/handler"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+)
+
+func createPaymentIntentAwaitingConfirmation(t *testing.T, h http.Handler, captureMethod string) string {
+	t.Helper()
+
+	_, business := doJSON(t, h, http.MethodPost, "/businesses", map[string]any{"name": "shop", "payment_method_types": []string{"card"}})
+	token := createCartToken(t, h, business["id"].(string), []map[string]any{{"item_id": "item_1", "price": 500}})
+	status, intent := doJSON(t, h, http.MethodPost, "/payment_intents", map[string]any{"cart_token": token})
+	require.Equal(t, http.StatusCreated, status, intent)
+	path := "/payment_intents/" + intent["id"].(string)
+
+	status, intent = doJSON(t, h, http.MethodPost, path+"/select", map[string]any{"payment_method_type": "card"})
+	require.Equal(t, http.StatusOK, status, intent)
+	status, intent = doJSON(t, h, http.MethodPost, path+"/provide", map[string]any{
+		"payment_method": map[string]any{
+			"type": "card",
+			"card": map[string]any{"number": "4242424242424242", "exp_year": 30, "exp_month": 12},
+		},
+		"capture_method": captureMethod,
+	})
+	require.Equal(t, http.StatusOK, status, intent)
+	return intent["id"].(string)
+}
+
+func providerEventBody(t *testing.T, id, eventType, paymentIntentID string) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{
+		"id":      id,
+		"type":    eventType,
+		"created": testHTTPNow.Unix(),
+		"data":    map[string]any{"payment_intent_id": paymentIntentID, "livemode": false},
+	})
+	require.NoError(t, err)
+	return body
+}
+
+func postProviderEvent(t *testing.T, h http.Handler, body []byte, signature string) (int, map[string]any) {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodPost, "/webhooks/provider", bytes.NewReader(body))
+	request.Header.Set(handler.WebhookSignatureHeader, signature)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, request)
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response), recorder.Body.String())
+	return recorder.Code, response
+}
+
+func signProviderEvent(body []byte, at time.Time) string {
+	signer := iasvc.NewHMACWebhookVerifier(testWebhookSecret, iasvc.DefaultWebhookTolerance, iasvc.NewFakeClock(at))
+	return signer.Sign(body, at)
+}
+
+func TestProviderWebhook_ShouldDispatchSignedEvents(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextRequiresAction)
+	id := createPaymentIntentAwaitingConfirmation(t, h, "automatic")
+
+	status, intent := doJSON(t, h, http.MethodPost, "/payment_intents/"+id+"/confirm", nil)
+	require.Equal(t, http.StatusOK, status, intent)
+	require.Equal(t, "requires_action", intent["status"])
+
+	body := providerEventBody(t, "evt_1", "action_completed", id)
+	status, response := postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "applied", response["status"])
+
+	_, intent = doJSON(t, h, http.MethodGet, "/payment_intents/"+id, nil)
+	assert.Equal(t, "processing", intent["status"])
+
+	body = providerEventBody(t, "evt_2", "payment_succeeded", id)
+	status, response = postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "applied", response["status"])
+
+	_, intent = doJSON(t, h, http.MethodGet, "/payment_intents/"+id, nil)
+	assert.Equal(t, "succeeded", intent["status"])
+	seqNr := intent["seq_nr"]
+
+	// 同じイベント ID の再送は適用しない
+	status, response = postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow.Add(time.Minute)))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "duplicate", response["status"])
+
+	_, intent = doJSON(t, h, http.MethodGet, "/payment_intents/"+id, nil)
+	assert.Equal(t, seqNr, intent["seq_nr"])
+}
+
+func TestProviderWebhook_ShouldParkEventsThatArriveEarly(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextRequiresAction)
+	id := createPaymentIntentAwaitingConfirmation(t, h, "automatic")
+	_, intent := doJSON(t, h, http.MethodPost, "/payment_intents/"+id+"/confirm", nil)
+	require.Equal(t, "requires_action", intent["status"])
+
+	body := providerEventBody(t, "evt_succeeded", "payment_succeeded", id)
+	status, response := postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "parked", response["status"])
+
+	_, intent = doJSON(t, h, http.MethodGet, "/payment_intents/"+id, nil)
+	assert.Equal(t, "requires_action", intent["status"])
+
+	body = providerEventBody(t, "evt_action", "action_completed", id)
+	status, response = postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "applied", response["status"])
+
+	_, intent = doJSON(t, h, http.MethodGet, "/payment_intents/"+id, nil)
+	assert.Equal(t, "succeeded", intent["status"])
+}
+
+func TestProviderWebhook_ShouldAcknowledgeUnhandledEventTypes(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+
+	body := providerEventBody(t, "evt_1", "customer.created", "pi_1")
+	status, response := postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "ignored", response["status"])
+}
+
+func TestProviderWebhook_ShouldRejectUnauthenticatedRequests(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+	body := providerEventBody(t, "evt_1", "payment_succeeded", "pi_1")
+
+	otherSecret := iasvc.NewHMACWebhookVerifier([]byte("whsec_other"), iasvc.DefaultWebhookTolerance, iasvc.NewFakeClock(testHTTPNow))
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+	}{
+		{name: "missing", body: body, signature: ""},
+		{name: "malformed", body: body, signature: "v1=abc"},
+		{name: "wrong secret", body: body, signature: otherSecret.Sign(body, testHTTPNow)},
+		{name: "tampered body", body: providerEventBody(t, "evt_1", "payment_succeeded", "pi_2"), signature: signProviderEvent(body, testHTTPNow)},
+		{name: "too old", body: body, signature: signProviderEvent(body, testHTTPNow.Add(-iasvc.DefaultWebhookTolerance-time.Second))},
+		{name: "too far ahead", body: body, signature: signProviderEvent(body, testHTTPNow.Add(iasvc.DefaultWebhookTolerance+time.Second))},
+		// スキーマ違反の本文でも、署名がなければスキーマの手がかりを返さない
+		{name: "unsigned body against the schema", body: []byte(`{"id":1}`), signature: ""},
+		{name: "unsigned empty body", body: nil, signature: "v1=abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := postProviderEvent(t, h, tt.body, tt.signature)
+			assert.Equal(t, http.StatusUnauthorized, status)
+			assert.Equal(t, "unauthorized", response["error"].(map[string]any)["code"])
+		})
+	}
+}
+
+func TestProviderWebhook_ShouldValidateSignedBodyAgainstTheSchema(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+	body := []byte(`{"id":1}`)
+
+	status, response := postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow))
+	assert.Equal(t, http.StatusBadRequest, status, response)
+}
+
+func TestProviderWebhook_ShouldAcceptAnyRotatedSecret(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+	body := providerEventBody(t, "evt_1", "payment_succeeded", "pi_missing")
+
+	other := iasvc.NewHMACWebhookVerifier([]byte("whsec_other"), iasvc.DefaultWebhookTolerance, iasvc.NewFakeClock(testHTTPNow))
+	_, current, _ := strings.Cut(signProviderEvent(body, testHTTPNow), ",")
+	signature := other.Sign(body, testHTTPNow) + "," + current
+
+	status, response := postProviderEvent(t, h, body, signature)
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "parked", response["status"])
+}
+
+func TestProviderWebhook_ShouldApplyEventsThatArriveBeforeTheIntentIsConfirmed(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+	id := createPaymentIntentAwaitingConfirmation(t, h, "automatic")
+
+	body := providerEventBody(t, "evt_succeeded", "payment_succeeded", id)
+	status, response := postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "parked", response["status"])
+
+	status, intent := doJSON(t, h, http.MethodPost, "/payment_intents/"+id+"/confirm", nil)
+	require.Equal(t, http.StatusOK, status, intent)
+
+	_, intent = doJSON(t, h, http.MethodGet, "/payment_intents/"+id, nil)
+	assert.Equal(t, "succeeded", intent["status"])
+
+	status, orphaned := getOrphanedProviderEvents(t, h, testOperatorToken)
+	require.Equal(t, http.StatusOK, status, orphaned)
+	assert.Empty(t, orphaned["events"])
+}
+
+func TestProviderWebhook_ShouldRestrictOrphanedEventsToOperators(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+
+	for _, token := range []string{"", "wrong"} {
+		status, response := getOrphanedProviderEvents(t, h, token)
+		assert.Equal(t, http.StatusUnauthorized, status, response)
+		assert.Equal(t, "unauthorized", response["error"].(map[string]any)["code"])
+	}
+
+	status, response := getOrphanedProviderEvents(t, h, testOperatorToken)
+	assert.Equal(t, http.StatusOK, status, response)
+}
+
+func getOrphanedProviderEvents(t *testing.T, h http.Handler, token string) (int, map[string]any) {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/provider_events/orphaned", nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, request)
+
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response), recorder.Body.String())
+	return recorder.Code, response
+}
+
+func TestProviderWebhook_ShouldFailProcessingIntentWithDeclineDetails(t *testing.T) {
+	h := newTestHTTPHandler(domain.PaymentConfirmationNextProcessing)
+	id := createPaymentIntentAwaitingConfirmation(t, h, "automatic")
+
+	status, intent := doJSON(t, h, http.MethodPost, "/payment_intents/"+id+"/confirm", nil)
+	require.Equal(t, http.StatusOK, status, intent)
+	require.Equal(t, "processing", intent["status"])
+
+	body, err := json.Marshal(map[string]any{
+		"id":      "evt_failed",
+		"type":    "payment_failed",
+		"created": testHTTPNow.Unix(),
+		"data": map[string]any{
+			"payment_intent_id": id,
+			"failure": map[string]any{
+				"code":             "insufficient_funds",
+				"category":         "insufficient_funds",
+				"retryable":        true,
+				"customer_message": "残高が不足しています",
+			},
+		},
+	})
+	require.NoError(t, err)
+	status, response := postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "applied", response["status"])
+
+	_, intent = doJSON(t, h, http.MethodGet, "/payment_intents/"+id, nil)
+	assert.Equal(t, "requires_payment_method", intent["status"])
+	assert.Equal(t, "insufficient_funds", intent["failure_reason"])
+	attempts := intent["attempts"].([]any)
+	last := attempts[len(attempts)-1].(map[string]any)
+	assert.Equal(t, "failed", last["outcome"])
+	assert.Equal(t, "残高が不足しています", last["customer_message"])
+	seqNr := intent["seq_nr"]
+
+	// 重複配信は状態を変えない
+	status, response = postProviderEvent(t, h, body, signProviderEvent(body, testHTTPNow.Add(time.Minute)))
+	require.Equal(t, http.StatusOK, status, response)
+	assert.Equal(t, "duplicate", response["status"])
+
+	_, intent = doJSON(t, h, http.MethodGet, "/payment_intents/"+id, nil)
+	assert.Equal(t, seqNr, intent["seq_nr"])
+}
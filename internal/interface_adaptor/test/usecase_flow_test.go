@@ -2,6 +2,7 @@ package test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -14,18 +15,23 @@ import (
 
 func TestUseCaseFlow_ShouldPassThroughAllStubs(t *testing.T) {
 	ctx := t.Context()
-	businessIDGenerator := iasvc.NewFakeBusinessIDGenerator(domain.NewBusinessID("biz_123"))
+	businessIDGenerator := iasvc.NewFakeBusinessIDGenerator(domain.BusinessID("biz_123"))
 	businessRepo := iarepo.NewInMemoryBusinessRepository()
-	cartIDGenerator := iasvc.NewFakeCartIDGenerator(domain.NewCartID("cart_123"))
-	tokenService := iasvc.NewTokenService()
+	cartIDGenerator := iasvc.NewFakeCartIDGenerator(domain.CartID("cart_123"))
+	tokenService := iasvc.NewTokenService([]byte("cart_token_secret"))
+	cartRepo := iarepo.NewInMemoryCartRepository()
 	paymentIntentRepo := iarepo.NewInMemoryPaymentIntentRepository()
 	paymentIntentIDGenerator := iasvc.NewFakePaymentIntentIDGenerator(domain.PaymentIntentID("pi_123"))
-	paymentProvider := iasvc.NewPaymentMethodProviderService(domain.PaymentConfirmationNextRequiresAction)
+	// 決済手段ごとに別のアクワイアラを使う。スタブなので中身は同じ
+	paymentProviders := iasvc.NewPaymentMethodProviderRegistry(iasvc.PaymentMethodProviders{
+		domain.PaymentMethodTypeCard:   iasvc.NewPaymentMethodProviderService(domain.PaymentConfirmationNextRequiresAction),
+		domain.PaymentMethodTypePayPay: iasvc.NewPaymentMethodProviderService(domain.PaymentConfirmationNextRequiresAction),
+	}, nil)
+	clock := iasvc.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
 
 	// create business
 	createBusiness := usecase.NewCreateBusinessUseCase(businessIDGenerator, businessRepo)
 	businessOutput, err := createBusiness.Execute(ctx, usecase.CreateBusinessUseCaseInput{
-		BusinessID:         "biz_123",
 		Name:               "Test Business",
 		PaymentMethodTypes: domain.PaymentMethodTypes{domain.PaymentMethodTypeCard},
 	})
@@ -35,15 +41,15 @@ func TestUseCaseFlow_ShouldPassThroughAllStubs(t *testing.T) {
 	assert.Len(t, businessRepo.Events(), 1)
 
 	// create cart
-	createCart := usecase.NewCreateCartUseCase(cartIDGenerator)
+	createCart := usecase.NewCreateCartUseCase(cartIDGenerator, cartRepo)
 	createCartOutput, err := createCart.Execute(ctx, usecase.CreateCartUseCaseInput{
 		BusinessID: businessOutput.Business.ID,
-		Items: domain.NewCartItems(
-			domain.CartItem{
+		Items: domain.CartItems{
+			{
 				ItemID: domain.ItemID("item_123"),
 				Price:  domain.ItemPrice(120),
 			},
-		),
+		},
 	})
 	assert.NoError(t, err)
 	assert.NotNil(t, createCartOutput)
@@ -51,16 +57,17 @@ func TestUseCaseFlow_ShouldPassThroughAllStubs(t *testing.T) {
 	assert.Equal(t, businessOutput.Business.ID, createCartOutput.Cart.BusinessID)
 
 	// confirm cart token
-	confirmCart := usecase.NewConfirmCartUseCase(tokenService)
+	confirmCart := usecase.NewConfirmCartUseCase(tokenService, cartRepo)
 	confirmCartOutput, err := confirmCart.Execute(ctx, usecase.ConfirmCartUseCaseInput{
-		Cart: createCartOutput.Cart,
+		BusinessID: createCartOutput.Cart.BusinessID,
+		CartID:     createCartOutput.Cart.CartID,
 	})
 	assert.NoError(t, err)
 	assert.NotNil(t, confirmCartOutput)
 	assert.NotEmpty(t, confirmCartOutput.Token.Value)
 
 	// initialize payment intent
-	initializePaymentIntent := usecase.NewInitializePaymentIntentUseCase(tokenService, paymentIntentRepo, paymentIntentIDGenerator, businessRepo)
+	initializePaymentIntent := usecase.NewInitializePaymentIntentUseCase(tokenService, paymentIntentRepo, paymentIntentIDGenerator, businessRepo, clock)
 	paymentIntentOutput, err := initializePaymentIntent.Execute(ctx, usecase.InitializePaymentIntentUseCaseInput{
 		CartToken: confirmCartOutput.Token,
 	})
@@ -69,9 +76,11 @@ func TestUseCaseFlow_ShouldPassThroughAllStubs(t *testing.T) {
 	assert.Len(t, paymentIntentRepo.Events(), 1)
 	paymentIntentView, err := converter.ToPaymentIntentView(paymentIntentOutput.PaymentIntent)
 	assert.NoError(t, err)
+	assert.Equal(t, businessOutput.Business.ID, paymentIntentView.BusinessID)
+	assert.Equal(t, createCartOutput.Cart.CartID, paymentIntentView.CartID)
 
 	// select payment method
-	selectPaymentMethod := usecase.NewSelectPaymentMethodUseCase(paymentIntentRepo)
+	selectPaymentMethod := usecase.NewSelectPaymentMethodUseCase(paymentIntentRepo, clock)
 	selectPaymentMethodOutput, err := selectPaymentMethod.Execute(ctx, usecase.SelectPaymentMethodUseCaseInput{
 		PaymentIntentID:   paymentIntentOutput.PaymentIntentID,
 		PaymentMethodType: paymentIntentView.PaymentMethodTypes[0],
@@ -85,19 +94,22 @@ func TestUseCaseFlow_ShouldPassThroughAllStubs(t *testing.T) {
 	selectedView, err := converter.ToPaymentIntentView(*selectedPaymentIntent)
 	assert.NoError(t, err)
 
+	paymentMethod, err := domain.NewPaymentMethod(
+		selectedView.PaymentMethodType,
+		&domain.PaymentMethodCard{
+			Number:   "4242424242424242",
+			ExpYear:  25,
+			ExpMonth: 12,
+		},
+		nil,
+	)
+	assert.NoError(t, err)
+
 	providePaymentMethod := usecase.NewProvidePaymentMethodUseCase(paymentIntentRepo)
 	providePaymentMethodOutput, err := providePaymentMethod.Execute(ctx, usecase.ProvidePaymentMethodUseCaseInput{
 		PaymentIntentID: selectedView.ID,
 		CaptureMethod:   domain.PaymentCaptureMethodManual,
-		PaymentMethod: domain.NewPaymentMethod(
-			selectedView.PaymentMethodType,
-			&domain.PaymentMethodCard{
-				Number:   "4242424242424242",
-				ExpYear:  25,
-				ExpMonth: 12,
-			},
-			nil,
-		),
+		PaymentMethod:   paymentMethod,
 	})
 	assert.NoError(t, err)
 	assert.NotNil(t, providePaymentMethodOutput)
@@ -110,7 +122,7 @@ func TestUseCaseFlow_ShouldPassThroughAllStubs(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "requires_confirmation", latestView.Status)
 
-	confirmPaymentIntent := usecase.NewConfirmPaymentIntentUseCase(paymentIntentRepo, paymentProvider)
+	confirmPaymentIntent := usecase.NewConfirmPaymentIntentUseCase(paymentIntentRepo, paymentProviders, domain.PaymentAttemptPolicy{MaxFailedAttempts: 3}, clock)
 	confirmPaymentIntentOutput, err := confirmPaymentIntent.Execute(ctx, usecase.ConfirmPaymentIntentUseCaseInput{
 		PaymentIntentID: paymentIntentOutput.PaymentIntentID,
 	})
@@ -126,7 +138,7 @@ func TestUseCaseFlow_ShouldPassThroughAllStubs(t *testing.T) {
 	assert.Equal(t, "requires_action", confirmedView.Status)
 
 	// webhook after user completed 3DS action
-	handleActionResult := usecase.NewHandlePaymentActionResultUseCase(paymentIntentRepo)
+	handleActionResult := usecase.NewHandlePaymentActionResultUseCase(paymentIntentRepo, clock)
 	handleActionResultOutput, err := handleActionResult.Execute(ctx, usecase.HandlePaymentActionResultUseCaseInput{
 		PaymentIntentID: paymentIntentOutput.PaymentIntentID,
 	})
@@ -141,7 +153,7 @@ func TestUseCaseFlow_ShouldPassThroughAllStubs(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "requires_capture", actionHandledView.Status)
 
-	capturePaymentIntent := usecase.NewCapturePaymentIntentUseCase(paymentIntentRepo, paymentProvider)
+	capturePaymentIntent := usecase.NewCapturePaymentIntentUseCase(paymentIntentRepo, paymentProviders, domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, clock)
 	capturePaymentIntentOutput, err := capturePaymentIntent.Execute(ctx, usecase.CapturePaymentIntentUseCaseInput{
 		PaymentIntentID: paymentIntentOutput.PaymentIntentID,
 	})
@@ -1,11 +1,36 @@
 package contract
 
+import (
+	"errors"
+	"fmt"
+)
+
 type (
 	Validator interface {
 		Validate() error
 	}
 )
 
+// ErrInvalidArgument marks errors caused by invalid input; domain re-exports it as part of its error taxonomy.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Validate returns the first validation error, marked as ErrInvalidArgument.
+func Validate(vs ...Validator) error {
+	for _, v := range vs {
+		if v == nil {
+			panic("Validator is nil")
+		}
+		if err := v.Validate(); err != nil {
+			if errors.Is(err, ErrInvalidArgument) {
+				return err
+			}
+			return fmt.Errorf("%w: %w", ErrInvalidArgument, err)
+		}
+	}
+	return nil
+}
+
+// AssertValidatable is for invariants that only a programming error can break, e.g. an aggregate's own state.
 func AssertValidatable(v Validator) {
 	if v == nil {
 		panic("Validator is nil")
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+type (
+	// CartRepository keeps the carts businesses created so that only a stored cart can be sealed into a cart token.
+	// Save returns a domain.ErrConflict when the cart ID is already stored.
+	CartRepository interface {
+		FindBy(ctx context.Context, id domain.CartID) (*domain.Cart, error)
+		Save(ctx context.Context, cart domain.Cart) error
+	}
+)
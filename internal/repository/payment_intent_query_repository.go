@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+type (
+	// PaymentIntentCursor is an opaque position in a PaymentIntent listing; only the repository that issued it can interpret it.
+	PaymentIntentCursor string
+
+	PaymentIntentQuery struct {
+		BusinessID        domain.BusinessID
+		CartID            domain.CartID
+		Statuses          []domain.PaymentIntentStatus
+		PaymentMethodType domain.PaymentMethodType
+		CreatedFrom       time.Time
+		CreatedTo         time.Time
+		Cursor            PaymentIntentCursor
+		Limit             int
+	}
+
+	PaymentIntentPage struct {
+		PaymentIntents []domain.PaymentIntent
+		NextCursor     PaymentIntentCursor
+	}
+
+	PaymentIntentQueryRepository interface {
+		List(ctx context.Context, query PaymentIntentQuery) (PaymentIntentPage, error)
+	}
+)
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+type (
+	// PendingProviderEventRepository is the inbox of provider events that arrived before their PaymentIntent could
+	// take them. Save returns a domain.ErrConflict when the event ID is already parked.
+	PendingProviderEventRepository interface {
+		Exists(ctx context.Context, id domain.ProviderEventID) (bool, error)
+		Save(ctx context.Context, pending domain.PendingProviderEvent) error
+		// FindByPaymentIntentID returns the parked events in the order they occurred.
+		FindByPaymentIntentID(ctx context.Context, id domain.PaymentIntentID) ([]domain.PendingProviderEvent, error)
+		// List returns every parked event, oldest parked first.
+		List(ctx context.Context) ([]domain.PendingProviderEvent, error)
+		Delete(ctx context.Context, id domain.ProviderEventID) error
+	}
+)
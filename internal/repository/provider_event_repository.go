@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+)
+
+type (
+	// ProviderEventRepository remembers the provider events that were applied so that redeliveries and replays are
+	// not applied twice. Save returns a domain.ErrConflict when the event ID is already recorded.
+	ProviderEventRepository interface {
+		Exists(ctx context.Context, id domain.ProviderEventID) (bool, error)
+		Save(ctx context.Context, event domain.ProviderEvent) error
+	}
+)
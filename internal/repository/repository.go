@@ -2,9 +2,19 @@ package repository
 
 import (
 	"context"
+	"fmt"
+
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 )
 
+// ErrConcurrentModification is returned by Save when the aggregate was saved by someone else since it was loaded.
+// It is a domain.ErrConflict.
+var ErrConcurrentModification = fmt.Errorf("%w: aggregate was modified concurrently", domain.ErrConflict)
+
+// ErrActivePaymentIntentExists is returned by Save when a new payment intent is created for a cart that already has an
+// active one. It is a domain.ErrConflict.
+var ErrActivePaymentIntentExists = fmt.Errorf("%w: active payment intent already exists for cart", domain.ErrConflict)
+
 type (
 	Repository[AggregateID, Aggregate, Event any] interface {
 		FindBy(ctx context.Context, aggregateID AggregateID) (*Aggregate, error)
@@ -0,0 +1,9 @@
+package service
+
+import "time"
+
+type (
+	Clock interface {
+		Now() time.Time
+	}
+)
@@ -21,4 +21,8 @@ type (
 	PaymentIDGenerator interface {
 		IDGenerator[domain.PaymentIntentID]
 	}
+
+	RefundIDGenerator interface {
+		IDGenerator[domain.PaymentRefundID]
+	}
 )
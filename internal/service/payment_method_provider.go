@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 )
 
+var ErrPaymentMethodProviderNotFound = errors.New("payment method provider not found")
+
 type (
 	PaymentConfirmationRequest struct {
 		Intent domain.PaymentIntentRequiresConfirmation
@@ -17,12 +21,63 @@ type (
 	}
 
 	PaymentCaptureRequest struct {
+		Intent          domain.PaymentIntentRequiresCapture
+		Amount          domain.Money
+		AmountToCapture domain.Money
+		// Final が false の場合、プロバイダはオーソリの残額を保持したままにする
+		Final bool
+	}
+
+	PaymentVoidRequest struct {
 		Intent domain.PaymentIntentRequiresCapture
 		Amount domain.Money
 	}
 
+	PaymentIncrementAuthorizationRequest struct {
+		Intent          domain.PaymentIntentRequiresCapture
+		Amount          domain.Money
+		IncrementAmount domain.Money
+	}
+
+	PaymentRefundRequest struct {
+		Intent   domain.PaymentIntentSucceeded
+		RefundID domain.PaymentRefundID
+		Amount   domain.Money
+	}
+
+	// PaymentDeclineError is returned by providers that can classify a decline; other errors are treated as unclassified.
+	PaymentDeclineError struct {
+		Decline domain.PaymentDecline
+		Err     error
+	}
+
 	PaymentMethodProviderService interface {
 		ConfirmPaymentMethod(context.Context, PaymentConfirmationRequest) (PaymentConfirmationResult, error)
 		CapturePaymentIntent(context.Context, PaymentCaptureRequest) error
+		VoidAuthorization(context.Context, PaymentVoidRequest) error
+		IncrementAuthorization(context.Context, PaymentIncrementAuthorizationRequest) error
+		RefundPayment(context.Context, PaymentRefundRequest) error
+	}
+
+	// PaymentMethodProviderRegistry picks the provider (acquirer) that processes a payment method type, letting a
+	// business override the default. A type nobody processes wraps ErrPaymentMethodProviderNotFound.
+	PaymentMethodProviderRegistry interface {
+		ProviderFor(domain.BusinessID, domain.PaymentMethodType) (PaymentMethodProviderService, error)
 	}
 )
+
+func (e *PaymentDeclineError) Error() string {
+	return fmt.Sprintf("payment declined (%s, %s): %v", e.Decline.Category, e.Decline.Code, e.Err)
+}
+
+func (e *PaymentDeclineError) Unwrap() error {
+	return e.Err
+}
+
+func DeclineFrom(err error) (domain.PaymentDecline, bool) {
+	var declineErr *PaymentDeclineError
+	if errors.As(err, &declineErr) {
+		return declineErr.Decline, true
+	}
+	return domain.PaymentDecline{}, false
+}
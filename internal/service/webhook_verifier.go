@@ -0,0 +1,13 @@
+package service
+
+import "errors"
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+type (
+	// WebhookVerifier authenticates a provider webhook from its signature header and raw body.
+	// Failures wrap ErrInvalidWebhookSignature.
+	WebhookVerifier interface {
+		Verify(signature string, payload []byte) error
+	}
+)
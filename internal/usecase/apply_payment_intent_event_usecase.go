@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	ApplyPaymentIntentEventResult string
+
+	ApplyPaymentIntentEventUseCaseInput struct {
+		Event domain.ProviderEvent
+	}
+
+	ApplyPaymentIntentEventUseCaseOutput struct {
+		EventID domain.ProviderEventID
+		Result  ApplyPaymentIntentEventResult
+		// PaymentIntent is the intent after this event and any parked events it released; nil if it does not exist yet.
+		PaymentIntent domain.PaymentIntent
+		// Replayed lists the parked events applied after this one, in order.
+		Replayed []domain.ProviderEventID
+	}
+
+	ApplyPaymentIntentEventUseCase interface {
+		Execute(context.Context, ApplyPaymentIntentEventUseCaseInput) (*ApplyPaymentIntentEventUseCaseOutput, error)
+	}
+
+	// PaymentIntentEventHandlers are the use cases ApplyPaymentIntentEventUseCase dispatches to, one per event type.
+	PaymentIntentEventHandlers struct {
+		ActionResult     HandlePaymentActionResultUseCase
+		PaymentSucceeded HandlePaymentSucceededUseCase
+		PaymentFailed    HandlePaymentFailedUseCase
+		CaptureFailed    HandleCaptureFailedUseCase
+		RefundSucceeded  HandleRefundSucceededUseCase
+		RefundFailed     HandleRefundFailedUseCase
+	}
+
+	applyPaymentIntentEventUseCase struct {
+		applier *providerEventApplier
+	}
+
+	// providerEventApplier is shared by ApplyPaymentIntentEventUseCase and ReplayPendingProviderEventsUseCase.
+	providerEventApplier struct {
+		providerEventRepository        repository.ProviderEventRepository
+		pendingProviderEventRepository repository.PendingProviderEventRepository
+		paymentIntentRepository        repository.PaymentIntentRepository
+		handlers                       PaymentIntentEventHandlers
+		inboxPolicy                    domain.ProviderEventInboxPolicy
+		clock                          service.Clock
+	}
+)
+
+const (
+	ApplyPaymentIntentEventResultApplied   ApplyPaymentIntentEventResult = "applied"
+	ApplyPaymentIntentEventResultDuplicate ApplyPaymentIntentEventResult = "duplicate"
+	ApplyPaymentIntentEventResultParked    ApplyPaymentIntentEventResult = "parked"
+)
+
+func NewApplyPaymentIntentEventUseCase(
+	providerEventRepository repository.ProviderEventRepository,
+	pendingProviderEventRepository repository.PendingProviderEventRepository,
+	paymentIntentRepository repository.PaymentIntentRepository,
+	handlers PaymentIntentEventHandlers,
+	inboxPolicy domain.ProviderEventInboxPolicy,
+	clock service.Clock,
+) ApplyPaymentIntentEventUseCase {
+	return &applyPaymentIntentEventUseCase{
+		applier: newProviderEventApplier(
+			providerEventRepository,
+			pendingProviderEventRepository,
+			paymentIntentRepository,
+			handlers,
+			inboxPolicy,
+			clock,
+		),
+	}
+}
+
+func newProviderEventApplier(
+	providerEventRepository repository.ProviderEventRepository,
+	pendingProviderEventRepository repository.PendingProviderEventRepository,
+	paymentIntentRepository repository.PaymentIntentRepository,
+	handlers PaymentIntentEventHandlers,
+	inboxPolicy domain.ProviderEventInboxPolicy,
+	clock service.Clock,
+) *providerEventApplier {
+	if providerEventRepository == nil {
+		panic("providerEventRepository is nil")
+	}
+	if pendingProviderEventRepository == nil {
+		panic("pendingProviderEventRepository is nil")
+	}
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if handlers.ActionResult == nil ||
+		handlers.PaymentSucceeded == nil ||
+		handlers.PaymentFailed == nil ||
+		handlers.CaptureFailed == nil ||
+		handlers.RefundSucceeded == nil ||
+		handlers.RefundFailed == nil {
+		panic("handlers is incomplete")
+	}
+	contract.AssertValidatable(inboxPolicy)
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &providerEventApplier{
+		providerEventRepository:        providerEventRepository,
+		pendingProviderEventRepository: pendingProviderEventRepository,
+		paymentIntentRepository:        paymentIntentRepository,
+		handlers:                       handlers,
+		inboxPolicy:                    inboxPolicy,
+		clock:                          clock,
+	}
+}
+
+func (i ApplyPaymentIntentEventUseCaseInput) Validate() error {
+	return contract.Validate(i.Event)
+}
+
+func (u *applyPaymentIntentEventUseCase) Execute(ctx context.Context, input ApplyPaymentIntentEventUseCaseInput) (*ApplyPaymentIntentEventUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+	event, a := input.Event, u.applier
+
+	// 孤立した通知は再生されないので、再送されたら受け直す
+	if err := a.releaseOrphaned(ctx, event); err != nil {
+		return nil, err
+	}
+	duplicate, err := a.seen(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate {
+		return &ApplyPaymentIntentEventUseCaseOutput{
+			EventID: event.ID,
+			Result:  ApplyPaymentIntentEventResultDuplicate,
+		}, nil
+	}
+
+	paymentIntent, err := a.paymentIntentRepository.FindBy(ctx, event.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil || !event.ReadyFor(*paymentIntent) {
+		pending := domain.PendingProviderEvent{Event: event, ParkedAt: a.clock.Now()}
+		if err := a.pendingProviderEventRepository.Save(ctx, pending); err != nil {
+			if errors.Is(err, domain.ErrConflict) {
+				return &ApplyPaymentIntentEventUseCaseOutput{
+					EventID: event.ID,
+					Result:  ApplyPaymentIntentEventResultDuplicate,
+				}, nil
+			}
+			return nil, err
+		}
+		output := &ApplyPaymentIntentEventUseCaseOutput{
+			EventID: event.ID,
+			Result:  ApplyPaymentIntentEventResultParked,
+		}
+		if paymentIntent != nil {
+			output.PaymentIntent = *paymentIntent
+		}
+		return output, nil
+	}
+
+	intent, err := a.apply(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ApplyPaymentIntentEventUseCaseOutput{
+		EventID:       event.ID,
+		Result:        ApplyPaymentIntentEventResultApplied,
+		PaymentIntent: intent,
+	}
+
+	// この通知で進んだ状態を待っていた通知を順に適用する
+	replayed, latest, err := a.replayPending(ctx, event.PaymentIntentID)
+	output.Replayed = replayed
+	if latest != nil {
+		output.PaymentIntent = latest
+	}
+	if err != nil {
+		return output, fmt.Errorf("replay parked provider events: %w", err)
+	}
+	return output, nil
+}
+
+func (a *providerEventApplier) seen(ctx context.Context, id domain.ProviderEventID) (bool, error) {
+	processed, err := a.providerEventRepository.Exists(ctx, id)
+	if err != nil || processed {
+		return processed, err
+	}
+	return a.pendingProviderEventRepository.Exists(ctx, id)
+}
+
+// releaseOrphaned drops the parked copy of a redelivered event once it is orphaned, so that the redelivery is
+// processed as a new event instead of being reported as a duplicate.
+func (a *providerEventApplier) releaseOrphaned(ctx context.Context, event domain.ProviderEvent) error {
+	pending, err := a.pendingProviderEventRepository.FindByPaymentIntentID(ctx, event.PaymentIntentID)
+	if err != nil {
+		return err
+	}
+	now := a.clock.Now()
+	for _, parked := range pending {
+		if parked.Event.ID == event.ID && a.inboxPolicy.IsOrphaned(parked, now) {
+			return a.pendingProviderEventRepository.Delete(ctx, event.ID)
+		}
+	}
+	return nil
+}
+
+// apply dispatches to the handler and records the event once the handler succeeded, so that a failure is retried
+// on redelivery.
+func (a *providerEventApplier) apply(ctx context.Context, event domain.ProviderEvent) (domain.PaymentIntent, error) {
+	var (
+		intent domain.PaymentIntent
+		err    error
+	)
+	switch event.Type {
+	case domain.ProviderEventTypeActionCompleted:
+		var output *HandlePaymentActionResultUseCaseOutput
+		output, err = a.handlers.ActionResult.Execute(ctx, HandlePaymentActionResultUseCaseInput{
+			PaymentIntentID: event.PaymentIntentID,
+		})
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+	case domain.ProviderEventTypePaymentSucceeded:
+		var output *HandlePaymentSucceededUseCaseOutput
+		output, err = a.handlers.PaymentSucceeded.Execute(ctx, HandlePaymentSucceededUseCaseInput{
+			PaymentIntentID: event.PaymentIntentID,
+		})
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+	case domain.ProviderEventTypePaymentFailed:
+		var output *HandlePaymentFailedUseCaseOutput
+		output, err = a.handlers.PaymentFailed.Execute(ctx, HandlePaymentFailedUseCaseInput{
+			PaymentIntentID: event.PaymentIntentID,
+			Decline:         event.Decline,
+		})
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+	case domain.ProviderEventTypeCaptureFailed:
+		var output *HandleCaptureFailedUseCaseOutput
+		output, err = a.handlers.CaptureFailed.Execute(ctx, HandleCaptureFailedUseCaseInput{
+			PaymentIntentID: event.PaymentIntentID,
+		})
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+	case domain.ProviderEventTypeRefundSucceeded:
+		var output *HandleRefundSucceededUseCaseOutput
+		output, err = a.handlers.RefundSucceeded.Execute(ctx, HandleRefundSucceededUseCaseInput{
+			PaymentIntentID: event.PaymentIntentID,
+			RefundID:        event.RefundID,
+		})
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+	case domain.ProviderEventTypeRefundFailed:
+		var output *HandleRefundFailedUseCaseOutput
+		output, err = a.handlers.RefundFailed.Execute(ctx, HandleRefundFailedUseCaseInput{
+			PaymentIntentID: event.PaymentIntentID,
+			RefundID:        event.RefundID,
+		})
+		if output != nil {
+			intent = output.PaymentIntent
+		}
+	default:
+		panic("unsupported provider event type")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.providerEventRepository.Save(ctx, event); err != nil && !errors.Is(err, domain.ErrConflict) {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// replayPending applies parked events for the intent until none of the remaining ones is ready. Orphaned events stay
+// parked for an operator.
+func (a *providerEventApplier) replayPending(ctx context.Context, id domain.PaymentIntentID) ([]domain.ProviderEventID, domain.PaymentIntent, error) {
+	var (
+		replayed []domain.ProviderEventID
+		latest   domain.PaymentIntent
+	)
+	for {
+		pending, err := a.pendingProviderEventRepository.FindByPaymentIntentID(ctx, id)
+		if err != nil {
+			return replayed, latest, err
+		}
+
+		progressed := false
+		now := a.clock.Now()
+		for _, parked := range pending {
+			if a.inboxPolicy.IsOrphaned(parked, now) {
+				continue
+			}
+			event := parked.Event
+			paymentIntent, err := a.paymentIntentRepository.FindBy(ctx, id)
+			if err != nil {
+				return replayed, latest, err
+			}
+			if paymentIntent == nil || !event.ReadyFor(*paymentIntent) {
+				continue
+			}
+
+			intent, err := a.apply(ctx, event)
+			if err != nil {
+				return replayed, latest, err
+			}
+			if err := a.pendingProviderEventRepository.Delete(ctx, event.ID); err != nil {
+				return replayed, latest, err
+			}
+			replayed = append(replayed, event.ID)
+			latest = intent
+			progressed = true
+		}
+		if !progressed {
+			return replayed, latest, nil
+		}
+	}
+}
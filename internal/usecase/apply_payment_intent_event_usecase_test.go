@@ -0,0 +1,354 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+type testProviderEventInbox struct {
+	apply        ApplyPaymentIntentEventUseCase
+	listOrphaned ListOrphanedProviderEventsUseCase
+	// paymentIntentRepository replays the parked events after every save.
+	paymentIntentRepository repository.PaymentIntentRepository
+}
+
+func newTestProviderEventInbox(repo *iarepo.InMemoryPaymentIntentRepository, clock *iasvc.FakeClock) testProviderEventInbox {
+	providerEventRepo := iarepo.NewInMemoryProviderEventRepository()
+	pendingRepo := iarepo.NewInMemoryPendingProviderEventRepository()
+	policy := domain.ProviderEventInboxPolicy{TTL: time.Hour}
+	handlers := PaymentIntentEventHandlers{
+		ActionResult:     NewHandlePaymentActionResultUseCase(repo, clock),
+		PaymentSucceeded: NewHandlePaymentSucceededUseCase(repo),
+		PaymentFailed:    NewHandlePaymentFailedUseCase(repo, domain.PaymentAttemptPolicy{MaxFailedAttempts: 3}, clock),
+		CaptureFailed:    NewHandleCaptureFailedUseCase(repo, clock),
+		RefundSucceeded:  NewHandleRefundSucceededUseCase(repo),
+		RefundFailed:     NewHandleRefundFailedUseCase(repo),
+	}
+	return testProviderEventInbox{
+		apply:        NewApplyPaymentIntentEventUseCase(providerEventRepo, pendingRepo, repo, handlers, policy, clock),
+		listOrphaned: NewListOrphanedProviderEventsUseCase(pendingRepo, policy, clock),
+		paymentIntentRepository: NewPaymentIntentRepositoryWithInbox(
+			repo,
+			NewReplayPendingProviderEventsUseCase(providerEventRepo, pendingRepo, repo, handlers, policy, clock),
+		),
+	}
+}
+
+func newTestApplyPaymentIntentEventUseCase(repo *iarepo.InMemoryPaymentIntentRepository) ApplyPaymentIntentEventUseCase {
+	return newTestProviderEventInbox(repo, iasvc.NewFakeClock(seedTime)).apply
+}
+
+func providerEvent(id string, eventType domain.ProviderEventType, paymentIntentID domain.PaymentIntentID) domain.ProviderEvent {
+	return domain.ProviderEvent{
+		ID:              domain.ProviderEventID(id),
+		Type:            eventType,
+		PaymentIntentID: paymentIntentID,
+		OccurredAt:      seedTime,
+	}
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldApplyOncePerEventID(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	event, processing, err := confirmation.StartProcessing()
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, processing))
+
+	useCase := newTestApplyPaymentIntentEventUseCase(repo)
+	input := ApplyPaymentIntentEventUseCaseInput{Event: providerEvent("evt_1", domain.ProviderEventTypePaymentSucceeded, confirmation.ID)}
+
+	output, err := useCase.Execute(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultApplied, output.Result)
+	assert.Equal(t, domain.PaymentIntentStatusSucceeded, output.PaymentIntent.Status())
+	events := len(repo.Events())
+
+	output, err = useCase.Execute(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultDuplicate, output.Result)
+	assert.Len(t, repo.Events(), events)
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldParkSuccessUntilActionCompletes(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	event, action, err := confirmation.RequireAction(seedTime)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, action))
+
+	useCase := newTestApplyPaymentIntentEventUseCase(repo)
+
+	output, err := useCase.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_succeeded", domain.ProviderEventTypePaymentSucceeded, confirmation.ID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultParked, output.Result)
+	assert.Equal(t, domain.PaymentIntentStatusRequiresAction, output.PaymentIntent.Status())
+
+	// 駐留中の再送も重複として扱う
+	output, err = useCase.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_succeeded", domain.ProviderEventTypePaymentSucceeded, confirmation.ID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultDuplicate, output.Result)
+
+	output, err = useCase.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_action", domain.ProviderEventTypeActionCompleted, confirmation.ID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultApplied, output.Result)
+	assert.Equal(t, []domain.ProviderEventID{"evt_succeeded"}, output.Replayed)
+	assert.Equal(t, domain.PaymentIntentStatusSucceeded, output.PaymentIntent.Status())
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldParkEventsForUnknownIntents(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	useCase := newTestApplyPaymentIntentEventUseCase(repo)
+
+	output, err := useCase.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_1", domain.ProviderEventTypePaymentSucceeded, "pi_unknown"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultParked, output.Result)
+	assert.Nil(t, output.PaymentIntent)
+	assert.Empty(t, repo.Events())
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldFailCaptureAfterProcessing(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	event, processing, err := intent.Capture(intent.Amount, domain.PaymentOverCapturePolicy{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, processing))
+
+	output, err := newTestApplyPaymentIntentEventUseCase(repo).Execute(ctx, ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_1", domain.ProviderEventTypeCaptureFailed, intent.ID),
+	})
+	require.NoError(t, err)
+	canceled, ok := output.PaymentIntent.(domain.PaymentIntentCanceled)
+	require.True(t, ok)
+	assert.Equal(t, domain.PaymentFailureReasonCaptureFailed, canceled.FailureReason)
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldApplyFailedAuthenticationDuringRequiresAction(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodManual)
+	event, action, err := confirmation.RequireAction(seedTime)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, action))
+
+	// 3DS に失敗した通知は processing を待たずに適用する
+	failed := providerEvent("evt_3ds_failed", domain.ProviderEventTypePaymentFailed, confirmation.ID)
+	failed.Decline = domain.PaymentDecline{
+		Code:      "authentication_failed",
+		Category:  domain.PaymentDeclineCategoryAuthenticationRequired,
+		Retryable: true,
+	}
+	output, err := newTestApplyPaymentIntentEventUseCase(repo).Execute(ctx, ApplyPaymentIntentEventUseCaseInput{Event: failed})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultApplied, output.Result)
+
+	intent, ok := output.PaymentIntent.(domain.PaymentIntentRequiresPaymentMethod)
+	require.True(t, ok)
+	assert.Equal(t, domain.PaymentFailureReasonAuthenticationRequired, intent.FailureReason)
+	require.NotEmpty(t, intent.Attempts)
+	assert.Equal(t, "authentication_failed", intent.Attempts[len(intent.Attempts)-1].ProviderErrorCode)
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldTreatSettledRefundAsApplied(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentSucceeded(t, ctx, repo)
+	useCase := newTestApplyPaymentIntentEventUseCase(repo)
+
+	refundEvent := providerEvent("evt_refund", domain.ProviderEventTypeRefundSucceeded, intent.ID)
+	refundEvent.RefundID = "re_1"
+
+	// 返金要求より先に届いた通知は待たせる
+	output, err := useCase.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{Event: refundEvent})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultParked, output.Result)
+
+	_, err = NewRefundPaymentIntentUseCase(repo, iasvc.NewFakeRefundIDGenerator("re_1"), paymentProvidersOf(&fakePaymentMethodProvider{})).Execute(ctx, RefundPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		Amount:          domain.Money(20),
+	})
+	require.NoError(t, err)
+	events := len(repo.Events())
+
+	// 保留していた通知で返金が確定し、再送は確定済みの返金に対する no-op になる
+	refundEvent.ID = "evt_refund_redelivered"
+	output, err = useCase.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{Event: refundEvent})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultApplied, output.Result)
+	assert.Equal(t, []domain.ProviderEventID{"evt_refund"}, output.Replayed)
+	assert.Len(t, repo.Events(), events+1)
+
+	latest, err := repo.FindBy(ctx, intent.ID)
+	require.NoError(t, err)
+	refunded := (*latest).(domain.PaymentIntentSucceeded)
+	assert.Equal(t, domain.PaymentRefundStatusSucceeded, refunded.Refunds[0].Status)
+	assert.Equal(t, domain.Money(20), refunded.Refunds.AmountRefunded())
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldSettlePendingRefundFromWebhook(t *testing.T) {
+	tests := []struct {
+		name           string
+		eventType      domain.ProviderEventType
+		wantStatus     domain.PaymentRefundStatus
+		wantRefunded   domain.Money
+		wantRefundable domain.Money
+	}{
+		{
+			name:           "refund succeeded",
+			eventType:      domain.ProviderEventTypeRefundSucceeded,
+			wantStatus:     domain.PaymentRefundStatusSucceeded,
+			wantRefunded:   20,
+			wantRefundable: 100,
+		},
+		{
+			name:           "refund failed",
+			eventType:      domain.ProviderEventTypeRefundFailed,
+			wantStatus:     domain.PaymentRefundStatusFailed,
+			wantRefunded:   0,
+			wantRefundable: 120,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := iarepo.NewInMemoryPaymentIntentRepository()
+			intent := seedPaymentIntentSucceeded(t, ctx, repo)
+			useCase := newTestApplyPaymentIntentEventUseCase(repo)
+
+			refund, err := NewRefundPaymentIntentUseCase(repo, iasvc.NewFakeRefundIDGenerator("re_1"), paymentProvidersOf(&fakePaymentMethodProvider{})).Execute(ctx, RefundPaymentIntentUseCaseInput{
+				PaymentIntentID: intent.ID,
+				Amount:          domain.Money(20),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, domain.PaymentRefundStatusPending, refund.PaymentIntent.(domain.PaymentIntentSucceeded).Refunds[0].Status)
+
+			refundEvent := providerEvent("evt_refund", tt.eventType, intent.ID)
+			refundEvent.RefundID = refund.RefundID
+			output, err := useCase.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{Event: refundEvent})
+			require.NoError(t, err)
+			assert.Equal(t, ApplyPaymentIntentEventResultApplied, output.Result)
+
+			settled := output.PaymentIntent.(domain.PaymentIntentSucceeded)
+			assert.Equal(t, tt.wantStatus, settled.Refunds[0].Status)
+			assert.Equal(t, tt.wantRefunded, settled.Refunds.AmountRefunded())
+			assert.Equal(t, tt.wantRefundable, settled.AmountRefundable())
+		})
+	}
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldReplayParkedEventsOnceTheIntentIsSaved(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	inbox := newTestProviderEventInbox(repo, iasvc.NewFakeClock(seedTime))
+
+	// Webhook が PaymentIntent の作成より先に届く
+	output, err := inbox.apply.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_succeeded", domain.ProviderEventTypePaymentSucceeded, "pi_fail_test"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultParked, output.Result)
+
+	// 作成や確認では processing に届かないので保留のまま
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	event, processing, err := confirmation.StartProcessing()
+	require.NoError(t, err)
+	require.NoError(t, inbox.paymentIntentRepository.Save(ctx, event, processing))
+
+	current, err := repo.FindBy(ctx, confirmation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentIntentStatusSucceeded, (*current).Status())
+
+	output, err = inbox.apply.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_succeeded", domain.ProviderEventTypePaymentSucceeded, confirmation.ID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultDuplicate, output.Result)
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldOrphanEventsPastTheInboxTTL(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime)
+	inbox := newTestProviderEventInbox(repo, clock)
+
+	_, err := inbox.apply.Execute(ctx, ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_succeeded", domain.ProviderEventTypePaymentSucceeded, "pi_fail_test"),
+	})
+	require.NoError(t, err)
+
+	orphaned, err := inbox.listOrphaned.Execute(ctx, ListOrphanedProviderEventsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Empty(t, orphaned.Events)
+
+	clock.Advance(time.Hour)
+	orphaned, err = inbox.listOrphaned.Execute(ctx, ListOrphanedProviderEventsUseCaseInput{})
+	require.NoError(t, err)
+	require.Len(t, orphaned.Events, 1)
+	assert.Equal(t, domain.ProviderEventID("evt_succeeded"), orphaned.Events[0].Event.ID)
+	assert.Equal(t, seedTime, orphaned.Events[0].ParkedAt)
+
+	// 期限切れの通知は再生しない
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	event, processing, err := confirmation.StartProcessing()
+	require.NoError(t, err)
+	require.NoError(t, inbox.paymentIntentRepository.Save(ctx, event, processing))
+
+	current, err := repo.FindBy(ctx, confirmation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentIntentStatusProcessing, (*current).Status())
+}
+
+func TestApplyPaymentIntentEventUseCase_ShouldProcessRedeliveredOrphanedEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime)
+	inbox := newTestProviderEventInbox(repo, clock)
+	input := ApplyPaymentIntentEventUseCaseInput{
+		Event: providerEvent("evt_succeeded", domain.ProviderEventTypePaymentSucceeded, "pi_fail_test"),
+	}
+
+	output, err := inbox.apply.Execute(ctx, input)
+	require.NoError(t, err)
+	require.Equal(t, ApplyPaymentIntentEventResultParked, output.Result)
+
+	// 期限内の再送は重複として扱う
+	output, err = inbox.apply.Execute(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultDuplicate, output.Result)
+
+	clock.Advance(time.Hour)
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	event, processing, err := confirmation.StartProcessing()
+	require.NoError(t, err)
+	require.NoError(t, inbox.paymentIntentRepository.Save(ctx, event, processing))
+
+	output, err = inbox.apply.Execute(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, ApplyPaymentIntentEventResultApplied, output.Result)
+	assert.Equal(t, domain.PaymentIntentStatusSucceeded, output.PaymentIntent.Status())
+
+	orphaned, err := inbox.listOrphaned.Execute(ctx, ListOrphanedProviderEventsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Empty(t, orphaned.Events)
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	CancelPaymentIntentUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+		// 利用者の操作による取消は customer、放置の検知などシステムによる取消は system として渡す
+		Initiator domain.PaymentCancellationInitiator
+		Reason    domain.PaymentCancellationReason
+	}
+
+	CancelPaymentIntentUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	CancelPaymentIntentUseCase interface {
+		Execute(context.Context, CancelPaymentIntentUseCaseInput) (*CancelPaymentIntentUseCaseOutput, error)
+	}
+
+	cancelPaymentIntentUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+		paymentProviders        service.PaymentMethodProviderRegistry
+	}
+)
+
+func NewCancelPaymentIntentUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	paymentProviders service.PaymentMethodProviderRegistry,
+) CancelPaymentIntentUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if paymentProviders == nil {
+		panic("paymentProviders is nil")
+	}
+	return &cancelPaymentIntentUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+		paymentProviders:        paymentProviders,
+	}
+}
+
+func (i CancelPaymentIntentUseCaseInput) Validate() error {
+	if err := contract.Validate(i.PaymentIntentID, i.Initiator, i.Reason); err != nil {
+		return err
+	}
+	if !i.Initiator.Allows(i.Reason) {
+		return fmt.Errorf("%w: %s cannot cancel for reason %q", domain.ErrInvalidArgument, i.Initiator, i.Reason)
+	}
+	return nil
+}
+
+func (u *cancelPaymentIntentUseCase) Execute(ctx context.Context, input CancelPaymentIntentUseCaseInput) (*CancelPaymentIntentUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	current := *paymentIntent
+	// ドメインで取消できることを確かめてからオーソリを解放する。分割キャプチャ済みのものはここで弾かれる
+	event, aggregate, err := current.Cancel(input.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	// オーソリを解放できなかった場合は状態を変えずに返す
+	if intent, ok := current.(domain.PaymentIntentRequiresCapture); ok {
+		paymentProvider, err := u.paymentProviders.ProviderFor(intent.BusinessID, intent.PaymentMethod.PaymentMethodType)
+		if err != nil {
+			return nil, err
+		}
+		if err := paymentProvider.VoidAuthorization(ctx, service.PaymentVoidRequest{
+			Intent: intent,
+			Amount: intent.AmountCapturable(),
+		}); err != nil {
+			return nil, fmt.Errorf("void authorization failed: %w", err)
+		}
+	}
+
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	return &CancelPaymentIntentUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   aggregate,
+	}, nil
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+)
+
+func TestCancelPaymentIntentUseCase_ShouldVoidAuthorizationWhenRequiresCapture(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	provider := &fakePaymentMethodProvider{}
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewCancelPaymentIntentUseCase(repo, paymentProvidersOf(provider))
+
+	output, err := useCase.Execute(ctx, CancelPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		Initiator:       domain.PaymentCancellationInitiatorCustomer,
+		Reason:          domain.PaymentCancellationReasonRequestedByCustomer,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.voided)
+	assert.Len(t, repo.Events(), 5)
+
+	result, ok := output.PaymentIntent.(domain.PaymentIntentCanceled)
+	require.True(t, ok)
+	assert.Equal(t, domain.PaymentCancellationReasonRequestedByCustomer, result.CancellationReason)
+	assert.Equal(t, intent.PaymentMethod, result.PaymentMethod)
+}
+
+func TestCancelPaymentIntentUseCase_ShouldKeepAuthorizationWhenVoidFails(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewCancelPaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{voidErr: errors.New("void failed")}))
+
+	output, err := useCase.Execute(ctx, CancelPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		Initiator:       domain.PaymentCancellationInitiatorCustomer,
+		Reason:          domain.PaymentCancellationReasonFraudulent,
+	})
+
+	require.Error(t, err)
+	assert.Nil(t, output)
+	assert.Len(t, repo.Events(), 4)
+
+	latest, err := repo.FindBy(ctx, intent.ID)
+	require.NoError(t, err)
+	assert.IsType(t, domain.PaymentIntentRequiresCapture{}, *latest)
+}
+
+func TestCancelPaymentIntentUseCase_ShouldRejectSucceededIntent(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentSucceeded(t, ctx, repo)
+	useCase := NewCancelPaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}))
+
+	_, err := useCase.Execute(ctx, CancelPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		Initiator:       domain.PaymentCancellationInitiatorCustomer,
+		Reason:          domain.PaymentCancellationReasonDuplicate,
+	})
+
+	require.ErrorIs(t, err, domain.ErrInvalidStateTransition)
+	assert.Len(t, repo.Events(), 5)
+}
+
+func TestCancelPaymentIntentUseCase_ShouldReturnTypedErrorsInsteadOfPanicking(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	useCase := NewCancelPaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}))
+
+	_, err := useCase.Execute(ctx, CancelPaymentIntentUseCaseInput{
+		PaymentIntentID: "pi_missing",
+		Initiator:       domain.PaymentCancellationInitiatorCustomer,
+		Reason:          domain.PaymentCancellationReasonDuplicate,
+	})
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	var notFound domain.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "pi_missing", notFound.ID)
+
+	_, err = useCase.Execute(ctx, CancelPaymentIntentUseCaseInput{
+		PaymentIntentID: "pi_missing",
+		Initiator:       domain.PaymentCancellationInitiatorCustomer,
+		Reason:          "lost_interest",
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidArgument)
+
+	intent := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	_, _, err = intent.Complete()
+	require.ErrorIs(t, err, domain.ErrInvalidStateTransition)
+}
+
+func TestCancelPaymentIntentUseCase_ShouldRestrictReasonsByInitiator(t *testing.T) {
+	tests := []struct {
+		name      string
+		initiator domain.PaymentCancellationInitiator
+		reason    domain.PaymentCancellationReason
+		wantErr   bool
+	}{
+		{name: "customer requests", initiator: domain.PaymentCancellationInitiatorCustomer, reason: domain.PaymentCancellationReasonRequestedByCustomer},
+		{name: "customer cannot abandon", initiator: domain.PaymentCancellationInitiatorCustomer, reason: domain.PaymentCancellationReasonAbandoned, wantErr: true},
+		{name: "system abandons", initiator: domain.PaymentCancellationInitiatorSystem, reason: domain.PaymentCancellationReasonAbandoned},
+		{name: "system flags fraud", initiator: domain.PaymentCancellationInitiatorSystem, reason: domain.PaymentCancellationReasonFraudulent},
+		{name: "expiry has its own transition", initiator: domain.PaymentCancellationInitiatorSystem, reason: domain.PaymentCancellationReasonAuthorizationExpired, wantErr: true},
+		{name: "attempt limit has its own transition", initiator: domain.PaymentCancellationInitiatorSystem, reason: domain.PaymentCancellationReasonMaxAttemptsExceeded, wantErr: true},
+		{name: "initiator is required", reason: domain.PaymentCancellationReasonRequestedByCustomer, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := iarepo.NewInMemoryPaymentIntentRepository()
+			intent := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+			events := len(repo.Events())
+			useCase := NewCancelPaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}))
+
+			output, err := useCase.Execute(ctx, CancelPaymentIntentUseCaseInput{
+				PaymentIntentID: intent.ID,
+				Initiator:       tt.initiator,
+				Reason:          tt.reason,
+			})
+			if tt.wantErr {
+				require.ErrorIs(t, err, domain.ErrInvalidArgument)
+				assert.Len(t, repo.Events(), events)
+				return
+			}
+			require.NoError(t, err)
+			canceled, ok := output.PaymentIntent.(domain.PaymentIntentCanceled)
+			require.True(t, ok)
+			assert.Equal(t, tt.reason, canceled.CancellationReason)
+		})
+	}
+}
+
+func TestCancelPaymentIntentUseCase_ShouldKeepAuthorizationAfterPartialCapture(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	provider := &fakePaymentMethodProvider{}
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	event, partial, err := intent.CapturePartially(domain.Money(20))
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, partial))
+	useCase := NewCancelPaymentIntentUseCase(repo, paymentProvidersOf(provider))
+
+	_, err = useCase.Execute(ctx, CancelPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		Initiator:       domain.PaymentCancellationInitiatorCustomer,
+		Reason:          domain.PaymentCancellationReasonRequestedByCustomer,
+	})
+
+	// 残りのオーソリは最終キャプチャで閉じるので、プロバイダには解放を依頼しない
+	require.ErrorIs(t, err, domain.ErrInvalidStateTransition)
+	assert.Equal(t, 0, provider.voided)
+
+	latest, err := repo.FindBy(ctx, intent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, partial, *latest)
+}
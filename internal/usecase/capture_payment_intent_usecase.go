@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
@@ -14,6 +13,10 @@ import (
 type (
 	CapturePaymentIntentUseCaseInput struct {
 		PaymentIntentID domain.PaymentIntentID
+		// AmountToCapture を省略した場合はオーソリの残額をすべてキャプチャする
+		AmountToCapture domain.Money
+		// MultiCapture が true の場合はオーソリを残したまま分割キャプチャする
+		MultiCapture bool
 	}
 
 	CapturePaymentIntentUseCaseOutput struct {
@@ -27,52 +30,100 @@ type (
 
 	capturePaymentIntentUseCase struct {
 		paymentIntentRepository repository.PaymentIntentRepository
-		paymentProvider         service.PaymentMethodProviderService
+		paymentProviders        service.PaymentMethodProviderRegistry
+		overCapturePolicy       domain.PaymentOverCapturePolicy
+		captureDeadlinePolicy   domain.PaymentCaptureDeadlinePolicy
+		clock                   service.Clock
 	}
 )
 
 func NewCapturePaymentIntentUseCase(
 	paymentIntentRepository repository.PaymentIntentRepository,
-	paymentProvider service.PaymentMethodProviderService,
+	paymentProviders service.PaymentMethodProviderRegistry,
+	overCapturePolicy domain.PaymentOverCapturePolicy,
+	captureDeadlinePolicy domain.PaymentCaptureDeadlinePolicy,
+	clock service.Clock,
 ) CapturePaymentIntentUseCase {
 	if paymentIntentRepository == nil {
 		panic("paymentIntentRepository is nil")
 	}
-	if paymentProvider == nil {
-		panic("paymentProvider is nil")
+	if paymentProviders == nil {
+		panic("paymentProviders is nil")
+	}
+	if clock == nil {
+		panic("clock is nil")
 	}
 	return &capturePaymentIntentUseCase{
 		paymentIntentRepository: paymentIntentRepository,
-		paymentProvider:         paymentProvider,
+		paymentProviders:        paymentProviders,
+		overCapturePolicy:       overCapturePolicy,
+		captureDeadlinePolicy:   captureDeadlinePolicy,
+		clock:                   clock,
 	}
 }
 
 func (i CapturePaymentIntentUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.PaymentIntentID)
-	return nil
+	return contract.Validate(i.PaymentIntentID)
 }
 
 func (u *capturePaymentIntentUseCase) Execute(ctx context.Context, input CapturePaymentIntentUseCaseInput) (*CapturePaymentIntentUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
 	if err != nil {
 		return nil, err
 	}
 	if paymentIntent == nil {
-		return nil, errors.New("payment intent not found")
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
 	}
 
 	intent, ok := (*paymentIntent).(domain.PaymentIntentRequiresCapture)
 	if !ok {
-		return nil, errors.New("payment intent not ready for capture")
+		return nil, fmt.Errorf("%w: payment intent not ready for capture", domain.ErrInvalidStateTransition)
+	}
+	if intent.IsAuthorizationExpired(u.captureDeadlinePolicy, u.clock.Now()) {
+		return nil, fmt.Errorf("%w: payment intent authorization has expired", domain.ErrInvalidStateTransition)
+	}
+
+	paymentProvider, err := u.paymentProviders.ProviderFor(intent.BusinessID, intent.PaymentMethod.PaymentMethodType)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		event     domain.PaymentIntentEvent
+		aggregate domain.PaymentIntent
+	)
+
+	amountToCapture := input.AmountToCapture
+	if input.MultiCapture {
+		event, aggregate, err = intent.CapturePartially(amountToCapture)
+	} else {
+		if amountToCapture == 0 {
+			amountToCapture = intent.AmountCapturable()
+		}
+		event, aggregate, err = intent.Capture(amountToCapture, u.overCapturePolicy)
 	}
+	if err != nil {
+		return nil, err
+	}
+	_, final := aggregate.(domain.PaymentIntentProcessing)
 
-	if err = u.paymentProvider.CapturePaymentIntent(ctx, service.PaymentCaptureRequest{
-		Intent: intent,
-		Amount: intent.Amount,
+	if err = paymentProvider.CapturePaymentIntent(ctx, service.PaymentCaptureRequest{
+		Intent:          intent,
+		Amount:          intent.Amount,
+		AmountToCapture: amountToCapture,
+		Final:           final,
 	}); err != nil {
-		event, aggregate, failErr := intent.Fail(domain.PaymentFailureReasonCaptureFailed, false)
+		decline, _ := service.DeclineFrom(err)
+		if !final || intent.AmountCaptured > 0 || decline.Retryable {
+			// 分割キャプチャ中や再試行可能な拒否はオーソリを維持したまま失敗を返し、再キャプチャできるようにする
+			return nil, fmt.Errorf("capture payment intent failed: %w", err)
+		}
+
+		event, aggregate, failErr := intent.Fail(decline.FailureReasonOr(domain.PaymentFailureReasonCaptureFailed), false, u.clock.Now())
 		if failErr != nil {
 			return nil, failErr
 		}
@@ -86,11 +137,6 @@ func (u *capturePaymentIntentUseCase) Execute(ctx context.Context, input Capture
 		}, fmt.Errorf("capture payment intent failed: %w", err)
 	}
 
-	event, aggregate, err := intent.StartProcessing()
-	if err != nil {
-		return nil, err
-	}
-
 	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
 		return nil, err
 	}
@@ -10,6 +10,8 @@ import (
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
 )
 
 func TestCapturePaymentIntentUseCase_ShouldCancelOnProviderError(t *testing.T) {
@@ -17,7 +19,7 @@ func TestCapturePaymentIntentUseCase_ShouldCancelOnProviderError(t *testing.T) {
 	repo := iarepo.NewInMemoryPaymentIntentRepository()
 
 	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
-	useCase := NewCapturePaymentIntentUseCase(repo, &fakePaymentMethodProvider{captureErr: errors.New("capture failed")})
+	useCase := NewCapturePaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{captureErr: errors.New("capture failed")}), domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
 
 	output, err := useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
 		PaymentIntentID: intent.ID,
@@ -33,6 +35,193 @@ func TestCapturePaymentIntentUseCase_ShouldCancelOnProviderError(t *testing.T) {
 	assert.Equal(t, intent.PaymentMethod, result.PaymentMethod)
 }
 
+func TestCapturePaymentIntentUseCase_ShouldKeepAuthorizationOnRetryableDecline(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	provider := &fakePaymentMethodProvider{
+		captureErr: &service.PaymentDeclineError{
+			Decline: domain.PaymentDecline{
+				Code:      "processor_unavailable",
+				Category:  domain.PaymentDeclineCategoryProcessingError,
+				Retryable: true,
+			},
+			Err: errors.New("try again later"),
+		},
+	}
+	useCase := NewCapturePaymentIntentUseCase(repo, paymentProvidersOf(provider), domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+
+	_, err := useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+	})
+	require.Error(t, err)
+	assert.Len(t, repo.Events(), 4)
+
+	latest, err := repo.FindBy(ctx, intent.ID)
+	require.NoError(t, err)
+	_, ok := (*latest).(domain.PaymentIntentRequiresCapture)
+	assert.True(t, ok)
+}
+
+func TestCapturePaymentIntentUseCase_ShouldCapturePartialAmountAndReleaseRest(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewCapturePaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}), domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+
+	output, err := useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(100),
+	})
+
+	require.NoError(t, err)
+	result, ok := output.PaymentIntent.(domain.PaymentIntentProcessing)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(100), result.AmountCaptured)
+	assert.Equal(t, intent.Amount, result.Amount)
+
+	event, ok := repo.Events()[len(repo.Events())-1].(domain.PaymentIntentProcessingEvent)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(100), event.AmountCaptured)
+	assert.Equal(t, domain.Money(20), event.AmountReleased)
+}
+
+func TestCapturePaymentIntentUseCase_ShouldApplyOverCapturePolicy(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+
+	strict := NewCapturePaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}), domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+	_, err := strict.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(121),
+	})
+	require.Error(t, err)
+	assert.Len(t, repo.Events(), 4)
+
+	lenient := NewCapturePaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}), domain.PaymentOverCapturePolicy{MaxPercent: 10}, domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+	_, err = lenient.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(133),
+	})
+	require.Error(t, err)
+
+	output, err := lenient.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(132),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.Money(132), output.PaymentIntent.(domain.PaymentIntentProcessing).AmountCaptured)
+}
+
+func TestCapturePaymentIntentUseCase_ShouldCaptureInMultiplePieces(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewCapturePaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}), domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+	handleSucceeded := NewHandlePaymentSucceededUseCase(repo)
+
+	output, err := useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(40),
+		MultiCapture:    true,
+	})
+	require.NoError(t, err)
+	piece, ok := output.PaymentIntent.(domain.PaymentIntentRequiresCapture)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(40), piece.AmountCaptured)
+	assert.Equal(t, domain.Money(80), piece.AmountCapturable())
+
+	event, ok := repo.Events()[len(repo.Events())-1].(domain.PaymentIntentPartiallyCapturedEvent)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(40), event.CaptureAmount)
+	assert.Equal(t, domain.Money(40), event.AmountCaptured)
+
+	// 最終キャプチャ前に届いた決済完了通知では状態を進めない
+	succeededOutput, err := handleSucceeded.Execute(ctx, HandlePaymentSucceededUseCaseInput{PaymentIntentID: intent.ID})
+	require.NoError(t, err)
+	assert.IsType(t, domain.PaymentIntentRequiresCapture{}, succeededOutput.PaymentIntent)
+
+	_, err = useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(81),
+		MultiCapture:    true,
+	})
+	require.Error(t, err)
+
+	output, err = useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(30),
+	})
+	require.NoError(t, err)
+	processing, ok := output.PaymentIntent.(domain.PaymentIntentProcessing)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(70), processing.AmountCaptured)
+
+	final, ok := repo.Events()[len(repo.Events())-1].(domain.PaymentIntentProcessingEvent)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(30), final.CaptureAmount)
+	assert.Equal(t, domain.Money(70), final.AmountCaptured)
+	assert.Equal(t, domain.Money(50), final.AmountReleased)
+
+	succeededOutput, err = handleSucceeded.Execute(ctx, HandlePaymentSucceededUseCaseInput{PaymentIntentID: intent.ID})
+	require.NoError(t, err)
+	succeeded, ok := succeededOutput.PaymentIntent.(domain.PaymentIntentSucceeded)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(70), succeeded.AmountCaptured)
+}
+
+func TestCapturePaymentIntentUseCase_ShouldFinishWhenPiecesUseUpAuthorization(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewCapturePaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}), domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+
+	_, err := useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(60),
+		MultiCapture:    true,
+	})
+	require.NoError(t, err)
+
+	output, err := useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		AmountToCapture: domain.Money(60),
+		MultiCapture:    true,
+	})
+	require.NoError(t, err)
+	processing, ok := output.PaymentIntent.(domain.PaymentIntentProcessing)
+	require.True(t, ok)
+	assert.Equal(t, intent.Amount, processing.AmountCaptured)
+}
+
+func TestCapturePaymentIntentUseCase_ShouldRejectCaptureAfterDeadline(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime.Add(domain.DefaultPaymentCaptureWindow))
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewCapturePaymentIntentUseCase(
+		repo,
+		paymentProvidersOf(&fakePaymentMethodProvider{}),
+		domain.PaymentOverCapturePolicy{},
+		domain.PaymentCaptureDeadlinePolicy{},
+		clock,
+	)
+
+	_, err := useCase.Execute(ctx, CapturePaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+	})
+
+	require.Error(t, err)
+	assert.Len(t, repo.Events(), 4)
+}
+
 func seedPaymentIntentRequiresCapture(
 	t *testing.T,
 	ctx context.Context,
@@ -41,7 +230,7 @@ func seedPaymentIntentRequiresCapture(
 	t.Helper()
 
 	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodManual)
-	event, aggregate, err := confirmation.RequireCapture()
+	event, aggregate, err := confirmation.RequireCapture(seedTime)
 	require.NoError(t, err)
 	require.NoError(t, repo.Save(ctx, event, aggregate))
 
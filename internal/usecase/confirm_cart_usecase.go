@@ -5,12 +5,14 @@ import (
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
 )
 
 type (
 	ConfirmCartUseCaseInput struct {
-		Cart domain.Cart
+		BusinessID domain.BusinessID
+		CartID     domain.CartID
 	}
 
 	ConfirmCartUseCaseOutput struct {
@@ -22,29 +24,44 @@ type (
 	}
 
 	confirmCartUseCase struct {
-		tokenService service.TokenService
+		tokenService   service.TokenService
+		cartRepository repository.CartRepository
 	}
 )
 
-func NewConfirmCartUseCase(tokenService service.TokenService) ConfirmCartUseCase {
+func NewConfirmCartUseCase(tokenService service.TokenService, cartRepository repository.CartRepository) ConfirmCartUseCase {
 	if tokenService == nil {
 		panic("tokenService is nil")
 	}
+	if cartRepository == nil {
+		panic("cartRepository is nil")
+	}
 	return &confirmCartUseCase{
-		tokenService: tokenService,
+		tokenService:   tokenService,
+		cartRepository: cartRepository,
 	}
 }
 
 func (i ConfirmCartUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.Cart)
-	return nil
+	return contract.Validate(i.BusinessID, i.CartID)
 }
 
 func (u *confirmCartUseCase) Execute(ctx context.Context, input ConfirmCartUseCaseInput) (*ConfirmCartUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	// クライアントが送った明細ではなく、保存済みのカートに署名する
+	cart, err := u.cartRepository.FindBy(ctx, input.CartID)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil || cart.BusinessID != input.BusinessID {
+		return nil, domain.NewCartNotFoundError(input.CartID)
+	}
 
 	token, err := u.tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{
-		Cart: input.Cart,
+		Cart: *cart,
 	})
 	if err != nil {
 		return nil, err
@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
@@ -27,53 +26,75 @@ type (
 
 	confirmPaymentIntentUseCase struct {
 		paymentIntentRepository repository.PaymentIntentRepository
-		paymentProvider         service.PaymentMethodProviderService
+		paymentProviders        service.PaymentMethodProviderRegistry
+		attemptPolicy           domain.PaymentAttemptPolicy
+		clock                   service.Clock
 	}
 )
 
 func NewConfirmPaymentIntentUseCase(
 	paymentIntentRepository repository.PaymentIntentRepository,
-	paymentProvider service.PaymentMethodProviderService,
+	paymentProviders service.PaymentMethodProviderRegistry,
+	attemptPolicy domain.PaymentAttemptPolicy,
+	clock service.Clock,
 ) ConfirmPaymentIntentUseCase {
 	if paymentIntentRepository == nil {
 		panic("paymentIntentRepository is nil")
 	}
-	if paymentProvider == nil {
-		panic("paymentProvider is nil")
+	if paymentProviders == nil {
+		panic("paymentProviders is nil")
+	}
+	contract.AssertValidatable(attemptPolicy)
+	if clock == nil {
+		panic("clock is nil")
 	}
 	return &confirmPaymentIntentUseCase{
 		paymentIntentRepository: paymentIntentRepository,
-		paymentProvider:         paymentProvider,
+		paymentProviders:        paymentProviders,
+		attemptPolicy:           attemptPolicy,
+		clock:                   clock,
 	}
 }
 
 func (i ConfirmPaymentIntentUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.PaymentIntentID)
-	return nil
+	return contract.Validate(i.PaymentIntentID)
 }
 
 func (u *confirmPaymentIntentUseCase) Execute(ctx context.Context, input ConfirmPaymentIntentUseCaseInput) (*ConfirmPaymentIntentUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
 	if err != nil {
 		return nil, err
 	}
 	if paymentIntent == nil {
-		return nil, errors.New("payment intent not found")
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
 	}
 
 	intent, ok := (*paymentIntent).(domain.PaymentIntentRequiresConfirmation)
 	if !ok {
-		return nil, errors.New("payment intent not ready for confirmation")
+		return nil, fmt.Errorf("%w: payment intent not ready for confirmation", domain.ErrInvalidStateTransition)
 	}
 
-	result, err := u.paymentProvider.ConfirmPaymentMethod(ctx, service.PaymentConfirmationRequest{
+	paymentProvider, err := u.paymentProviders.ProviderFor(intent.BusinessID, intent.PaymentMethod.PaymentMethodType)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := paymentProvider.ConfirmPaymentMethod(ctx, service.PaymentConfirmationRequest{
 		Intent: intent,
 		Amount: intent.Amount,
 	})
 	if err != nil {
-		event, aggregate, failErr := intent.Fail(domain.PaymentFailureReasonConfirmationFailed, true)
+		decline, ok := service.DeclineFrom(err)
+		if !ok {
+			// 分類できないエラーは再試行可能として扱う
+			decline = domain.PaymentDecline{Retryable: true}
+		}
+
+		event, aggregate, failErr := intent.FailConfirmation(decline, u.clock.Now(), u.attemptPolicy)
 		if failErr != nil {
 			return nil, failErr
 		}
@@ -87,7 +108,7 @@ func (u *confirmPaymentIntentUseCase) Execute(ctx context.Context, input Confirm
 		}, fmt.Errorf("confirm payment method failed: %w", err)
 	}
 
-	event, aggregate, err := intent.ApplyConfirmationResult(result.NextStatus)
+	event, aggregate, err := intent.ApplyConfirmationResult(result.NextStatus, u.clock.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -4,18 +4,26 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/converter"
 	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
 )
 
 type fakePaymentMethodProvider struct {
-	confirmErr error
-	captureErr error
+	confirmErr   error
+	captureErr   error
+	voidErr      error
+	voided       int
+	incrementErr error
+	incremented  int
+	refundErr    error
 }
 
 func (f *fakePaymentMethodProvider) ConfirmPaymentMethod(context.Context, service.PaymentConfirmationRequest) (service.PaymentConfirmationResult, error) {
@@ -29,12 +37,40 @@ func (f *fakePaymentMethodProvider) CapturePaymentIntent(context.Context, servic
 	return f.captureErr
 }
 
+func (f *fakePaymentMethodProvider) VoidAuthorization(context.Context, service.PaymentVoidRequest) error {
+	if f.voidErr != nil {
+		return f.voidErr
+	}
+	f.voided++
+	return nil
+}
+
+func (f *fakePaymentMethodProvider) IncrementAuthorization(context.Context, service.PaymentIncrementAuthorizationRequest) error {
+	if f.incrementErr != nil {
+		return f.incrementErr
+	}
+	f.incremented++
+	return nil
+}
+
+func (f *fakePaymentMethodProvider) RefundPayment(context.Context, service.PaymentRefundRequest) error {
+	return f.refundErr
+}
+
+// paymentProvidersOf routes every payment method type to provider.
+func paymentProvidersOf(provider service.PaymentMethodProviderService) service.PaymentMethodProviderRegistry {
+	return iasvc.NewPaymentMethodProviderRegistry(iasvc.PaymentMethodProviders{
+		domain.PaymentMethodTypeCard:   provider,
+		domain.PaymentMethodTypePayPay: provider,
+	}, nil)
+}
+
 func TestConfirmPaymentIntentUseCase_ShouldFailOnProviderError(t *testing.T) {
 	ctx := context.Background()
 	repo := iarepo.NewInMemoryPaymentIntentRepository()
 
 	intent := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
-	useCase := NewConfirmPaymentIntentUseCase(repo, &fakePaymentMethodProvider{confirmErr: errors.New("provider down")})
+	useCase := NewConfirmPaymentIntentUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{confirmErr: errors.New("provider down")}), domain.PaymentAttemptPolicy{}, iasvc.NewFakeClock(seedTime))
 
 	output, err := useCase.Execute(ctx, ConfirmPaymentIntentUseCaseInput{
 		PaymentIntentID: intent.ID,
@@ -49,3 +85,117 @@ func TestConfirmPaymentIntentUseCase_ShouldFailOnProviderError(t *testing.T) {
 	assert.Equal(t, domain.PaymentFailureReasonConfirmationFailed, result.FailureReason)
 	assert.Equal(t, domain.PaymentMethodTypeCard, result.PaymentMethodType)
 }
+
+func TestConfirmPaymentIntentUseCase_ShouldRecordAttemptsAndCancelAfterMaxFailures(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime)
+	provider := &fakePaymentMethodProvider{
+		confirmErr: &service.PaymentDeclineError{
+			Decline: domain.PaymentDecline{
+				Code:      "card_declined",
+				Category:  domain.PaymentDeclineCategoryDoNotHonor,
+				Retryable: true,
+			},
+			Err: errors.New("declined"),
+		},
+	}
+	useCase := NewConfirmPaymentIntentUseCase(repo, paymentProvidersOf(provider), domain.PaymentAttemptPolicy{MaxFailedAttempts: 3}, clock)
+
+	intent := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+
+	var output *ConfirmPaymentIntentUseCaseOutput
+	for attempt := 1; attempt <= 3; attempt++ {
+		clock.Advance(time.Minute)
+
+		var err error
+		output, err = useCase.Execute(ctx, ConfirmPaymentIntentUseCaseInput{PaymentIntentID: intent.ID})
+		require.Error(t, err)
+
+		if attempt < 3 {
+			retry, ok := output.PaymentIntent.(domain.PaymentIntentRequiresPaymentMethod)
+			require.True(t, ok)
+			event, aggregate, err := retry.RequireConfirmation(intent.PaymentMethod, domain.PaymentCaptureMethodAutomatic)
+			require.NoError(t, err)
+			require.NoError(t, repo.Save(ctx, event, aggregate))
+		}
+	}
+
+	canceled, ok := output.PaymentIntent.(domain.PaymentIntentCanceled)
+	require.True(t, ok)
+	assert.Equal(t, domain.PaymentCancellationReasonMaxAttemptsExceeded, canceled.CancellationReason)
+
+	view, err := converter.ToPaymentIntentView(canceled)
+	require.NoError(t, err)
+	require.Len(t, view.Attempts, 3)
+	for idx, attempt := range view.Attempts {
+		assert.Equal(t, domain.PaymentAttemptOutcomeFailed, attempt.Outcome)
+		assert.Equal(t, "card_declined", attempt.ProviderErrorCode)
+		assert.Equal(t, "************4242", attempt.PaymentMethod.Card.Number)
+		assert.Equal(t, seedTime.Add(time.Duration(idx+1)*time.Minute), attempt.AttemptedAt)
+	}
+}
+
+func TestConfirmPaymentIntentUseCase_ShouldCancelOnTerminalDecline(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	provider := &fakePaymentMethodProvider{
+		confirmErr: &service.PaymentDeclineError{
+			Decline: domain.PaymentDecline{
+				Code:            "expired_card",
+				Category:        domain.PaymentDeclineCategoryExpiredCard,
+				CustomerMessage: "カードの有効期限が切れています",
+			},
+			Err: errors.New("expired card"),
+		},
+	}
+
+	intent := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	useCase := NewConfirmPaymentIntentUseCase(repo, paymentProvidersOf(provider), domain.PaymentAttemptPolicy{}, iasvc.NewFakeClock(seedTime))
+
+	output, err := useCase.Execute(ctx, ConfirmPaymentIntentUseCaseInput{PaymentIntentID: intent.ID})
+	require.Error(t, err)
+
+	canceled, ok := output.PaymentIntent.(domain.PaymentIntentCanceled)
+	require.True(t, ok)
+	assert.Equal(t, domain.PaymentFailureReasonExpiredCard, canceled.FailureReason)
+	require.Len(t, canceled.Attempts, 1)
+	assert.Equal(t, domain.PaymentDeclineCategoryExpiredCard, canceled.Attempts[0].DeclineCategory)
+	assert.Equal(t, "カードの有効期限が切れています", canceled.Attempts[0].CustomerMessage)
+}
+
+func TestConfirmPaymentIntentUseCase_ShouldRouteToBusinessProvider(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	providers := iasvc.NewPaymentMethodProviderRegistry(
+		iasvc.PaymentMethodProviders{domain.PaymentMethodTypeCard: &fakePaymentMethodProvider{}},
+		map[domain.BusinessID]iasvc.PaymentMethodProviders{
+			intent.BusinessID: {domain.PaymentMethodTypeCard: &fakePaymentMethodProvider{confirmErr: errors.New("acquirer down")}},
+		},
+	)
+	useCase := NewConfirmPaymentIntentUseCase(repo, providers, domain.PaymentAttemptPolicy{}, iasvc.NewFakeClock(seedTime))
+
+	output, err := useCase.Execute(ctx, ConfirmPaymentIntentUseCaseInput{PaymentIntentID: intent.ID})
+	require.Error(t, err)
+	require.NotNil(t, output)
+	assert.Equal(t, domain.PaymentIntentStatusRequiresPaymentMethod, output.PaymentIntent.Status())
+}
+
+func TestConfirmPaymentIntentUseCase_ShouldRejectUnroutedPaymentMethodType(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	providers := iasvc.NewPaymentMethodProviderRegistry(iasvc.PaymentMethodProviders{
+		domain.PaymentMethodTypePayPay: &fakePaymentMethodProvider{},
+	}, nil)
+	useCase := NewConfirmPaymentIntentUseCase(repo, providers, domain.PaymentAttemptPolicy{}, iasvc.NewFakeClock(seedTime))
+
+	output, err := useCase.Execute(ctx, ConfirmPaymentIntentUseCaseInput{PaymentIntentID: intent.ID})
+	require.ErrorIs(t, err, service.ErrPaymentMethodProviderNotFound)
+	assert.Nil(t, output)
+	// プロバイダを決められない場合は試行として数えない
+	assert.Len(t, repo.Events(), 3)
+}
@@ -2,7 +2,7 @@ package usecase
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
 
@@ -12,7 +12,6 @@ import (
 
 type (
 	CreateBusinessUseCaseInput struct {
-		BusinessID         string
 		Name               string
 		PaymentMethodTypes domain.PaymentMethodTypes
 	}
@@ -48,18 +47,16 @@ func NewCreateBusinessUseCase(
 }
 
 func (i CreateBusinessUseCaseInput) Validate() error {
-	if len(i.BusinessID) == 0 {
-		return errors.New("business id is empty")
-	}
 	if len(i.Name) == 0 {
-		return errors.New("business name is empty")
+		return fmt.Errorf("%w: business name is empty", domain.ErrInvalidArgument)
 	}
-	contract.AssertValidatable(i.PaymentMethodTypes)
-	return nil
+	return contract.Validate(i.PaymentMethodTypes)
 }
 
 func (u *createBusinessUseCase) Execute(ctx context.Context, input CreateBusinessUseCaseInput) (*CreateBusinessUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	businessID, err := u.businessIDGenerator.GenerateID(ctx)
 	if err != nil {
@@ -72,11 +69,14 @@ func (u *createBusinessUseCase) Execute(ctx context.Context, input CreateBusines
 		input.Name,
 		input.PaymentMethodTypes,
 	)
-	business := domain.NewBusiness(
+	business, err := domain.NewBusiness(
 		businessID,
 		input.Name,
 		input.PaymentMethodTypes,
 	)
+	if err != nil {
+		return nil, err
+	}
 
 	err = u.businessRepository.Save(ctx, businessEvent, business)
 	if err != nil {
@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
 )
 
@@ -23,33 +25,46 @@ type (
 
 	createCartUseCase struct {
 		cartIDGenerator service.CartIDGenerator
+		cartRepository  repository.CartRepository
 	}
 )
 
-func NewCreateCartUseCase(cartIDGenerator service.CartIDGenerator) CreateCartUseCase {
+func NewCreateCartUseCase(cartIDGenerator service.CartIDGenerator, cartRepository repository.CartRepository) CreateCartUseCase {
 	if cartIDGenerator == nil {
 		panic("cartIDGenerator is nil")
 	}
+	if cartRepository == nil {
+		panic("cartRepository is nil")
+	}
 	return &createCartUseCase{
 		cartIDGenerator: cartIDGenerator,
+		cartRepository:  cartRepository,
 	}
 }
 
 func (i CreateCartUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.BusinessID)
-	contract.AssertValidatable(i.Items)
-	return nil
+	return contract.Validate(i.BusinessID, i.Items)
 }
 
 func (u *createCartUseCase) Execute(ctx context.Context, input CreateCartUseCaseInput) (*CreateCartUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	cartID, err := u.cartIDGenerator.GenerateID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	cart := domain.NewCart(input.BusinessID, cartID, input.Items)
+	cart, err := domain.NewCart(input.BusinessID, cartID, input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	// 確定時に署名するのは保存したカートだけにする
+	if err := u.cartRepository.Save(ctx, cart); err != nil {
+		return nil, err
+	}
 
 	return &CreateCartUseCaseOutput{
 		Cart: cart,
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	ExpireAbandonedIntentsUseCaseInput struct {
+		BusinessID domain.BusinessID
+	}
+
+	ExpireAbandonedIntentsUseCaseOutput struct {
+		ExpiredPaymentIntentIDs []domain.PaymentIntentID
+	}
+
+	ExpireAbandonedIntentsUseCase interface {
+		Execute(context.Context, ExpireAbandonedIntentsUseCaseInput) (*ExpireAbandonedIntentsUseCaseOutput, error)
+	}
+
+	expireAbandonedIntentsUseCase struct {
+		paymentIntentRepository      repository.PaymentIntentRepository
+		paymentIntentQueryRepository repository.PaymentIntentQueryRepository
+		abandonmentPolicy            domain.PaymentIntentAbandonmentPolicy
+		clock                        service.Clock
+	}
+)
+
+func NewExpireAbandonedIntentsUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	paymentIntentQueryRepository repository.PaymentIntentQueryRepository,
+	abandonmentPolicy domain.PaymentIntentAbandonmentPolicy,
+	clock service.Clock,
+) ExpireAbandonedIntentsUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if paymentIntentQueryRepository == nil {
+		panic("paymentIntentQueryRepository is nil")
+	}
+	contract.AssertValidatable(abandonmentPolicy)
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &expireAbandonedIntentsUseCase{
+		paymentIntentRepository:      paymentIntentRepository,
+		paymentIntentQueryRepository: paymentIntentQueryRepository,
+		abandonmentPolicy:            abandonmentPolicy,
+		clock:                        clock,
+	}
+}
+
+func (u *expireAbandonedIntentsUseCase) Execute(ctx context.Context, input ExpireAbandonedIntentsUseCaseInput) (*ExpireAbandonedIntentsUseCaseOutput, error) {
+	now := u.clock.Now()
+	expired := make([]domain.PaymentIntentID, 0)
+
+	statuses := u.abandonmentPolicy.Statuses()
+	if len(statuses) == 0 {
+		return &ExpireAbandonedIntentsUseCaseOutput{ExpiredPaymentIntentIDs: expired}, nil
+	}
+	slices.Sort(statuses)
+
+	query := repository.PaymentIntentQuery{
+		BusinessID: input.BusinessID,
+		Statuses:   statuses,
+	}
+	for {
+		page, err := u.paymentIntentQueryRepository.List(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, paymentIntent := range page.PaymentIntents {
+			id, ok, err := u.expire(ctx, paymentIntent, now)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				expired = append(expired, id)
+			}
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	return &ExpireAbandonedIntentsUseCaseOutput{
+		ExpiredPaymentIntentIDs: expired,
+	}, nil
+}
+
+func (u *expireAbandonedIntentsUseCase) expire(ctx context.Context, paymentIntent domain.PaymentIntent, now time.Time) (domain.PaymentIntentID, bool, error) {
+	var (
+		id              domain.PaymentIntentID
+		status          domain.PaymentIntentStatus
+		statusChangedAt time.Time
+	)
+
+	switch intent := paymentIntent.(type) {
+	case domain.PaymentIntentRequiresPaymentMethodType:
+		id, status, statusChangedAt = intent.ID, domain.PaymentIntentStatusRequiresPaymentMethodType, intent.StatusChangedAt
+	case domain.PaymentIntentRequiresPaymentMethod:
+		id, status, statusChangedAt = intent.ID, domain.PaymentIntentStatusRequiresPaymentMethod, intent.StatusChangedAt
+	case domain.PaymentIntentRequiresAction:
+		id, status, statusChangedAt = intent.ID, domain.PaymentIntentStatusRequiresAction, intent.StatusChangedAt
+	default:
+		return "", false, nil
+	}
+
+	if !u.abandonmentPolicy.IsAbandoned(status, statusChangedAt, now) {
+		return "", false, nil
+	}
+
+	event, aggregate, err := paymentIntent.Cancel(domain.PaymentCancellationReasonExpired)
+	if err != nil {
+		return "", false, err
+	}
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		// 他のスイーパーや顧客操作が先に更新した場合は次回の実行に任せる
+		if errors.Is(err, repository.ErrConcurrentModification) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	return id, true, nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+)
+
+func TestExpireAbandonedIntentsUseCase_ShouldCancelIntentsPastTheirTimeout(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime)
+	policy := domain.PaymentIntentAbandonmentPolicy{
+		Timeouts: map[domain.PaymentIntentStatus]time.Duration{
+			domain.PaymentIntentStatusRequiresPaymentMethodType: time.Hour,
+			domain.PaymentIntentStatusRequiresPaymentMethod:     2 * time.Hour,
+		},
+	}
+
+	typeOnly := seedGeneratedPaymentIntent(t, ctx, repo, "pi_type", "biz_a", seedTime)
+	selected := seedGeneratedPaymentIntent(t, ctx, repo, "pi_method", "biz_a", seedTime)
+	confirming := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+
+	useCase := NewExpireAbandonedIntentsUseCase(repo, repo, policy, clock)
+
+	// 支払い方法の選択待ちのタイムアウトは作成からではなく、選択した時点から測る
+	clock.Advance(30 * time.Minute)
+	event, aggregate, err := selected.RequirePaymentMethod(domain.PaymentMethodTypeCard, clock.Now())
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+
+	clock.Advance(29 * time.Minute)
+	output, err := useCase.Execute(ctx, ExpireAbandonedIntentsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Empty(t, output.ExpiredPaymentIntentIDs)
+
+	clock.Advance(time.Minute)
+	output, err = useCase.Execute(ctx, ExpireAbandonedIntentsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PaymentIntentID{typeOnly.ID}, output.ExpiredPaymentIntentIDs)
+
+	clock.Advance(time.Hour)
+	output, err = useCase.Execute(ctx, ExpireAbandonedIntentsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Empty(t, output.ExpiredPaymentIntentIDs, "two hours since creation but not since selection")
+
+	clock.Advance(30 * time.Minute)
+	output, err = useCase.Execute(ctx, ExpireAbandonedIntentsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PaymentIntentID{selected.ID}, output.ExpiredPaymentIntentIDs)
+
+	latest, err := repo.FindBy(ctx, typeOnly.ID)
+	require.NoError(t, err)
+	canceled, ok := (*latest).(domain.PaymentIntentCanceled)
+	require.True(t, ok)
+	assert.Equal(t, domain.PaymentCancellationReasonExpired, canceled.CancellationReason)
+
+	latest, err = repo.FindBy(ctx, confirming.ID)
+	require.NoError(t, err)
+	_, ok = (*latest).(domain.PaymentIntentRequiresConfirmation)
+	assert.True(t, ok)
+}
+
+func TestExpireAbandonedIntentsUseCase_ShouldExpireEachIntentOnceAcrossConcurrentSweepers(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime)
+
+	ids := []domain.PaymentIntentID{"pi_1", "pi_2", "pi_3", "pi_4", "pi_5"}
+	for _, id := range ids {
+		seedGeneratedPaymentIntent(t, ctx, repo, id, "biz_a", seedTime)
+	}
+	clock.Advance(24 * time.Hour)
+
+	const sweepers = 4
+	outputs := make([]*ExpireAbandonedIntentsUseCaseOutput, sweepers)
+	errs := make([]error, sweepers)
+	var wg sync.WaitGroup
+	for idx := range sweepers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			useCase := NewExpireAbandonedIntentsUseCase(repo, repo, domain.DefaultPaymentIntentAbandonmentPolicy(), clock)
+			outputs[idx], errs[idx] = useCase.Execute(ctx, ExpireAbandonedIntentsUseCaseInput{BusinessID: "biz_a"})
+		}()
+	}
+	wg.Wait()
+
+	expired := make([]domain.PaymentIntentID, 0, len(ids))
+	for idx := range sweepers {
+		require.NoError(t, errs[idx])
+		expired = append(expired, outputs[idx].ExpiredPaymentIntentIDs...)
+	}
+	assert.ElementsMatch(t, ids, expired)
+
+	canceledEvents := 0
+	for _, event := range repo.Events() {
+		if _, ok := event.(domain.PaymentIntentCanceledEvent); ok {
+			canceledEvents++
+		}
+	}
+	assert.Equal(t, len(ids), canceledEvents)
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	ExpireUncapturedIntentsUseCaseInput struct {
+		BusinessID domain.BusinessID
+	}
+
+	ExpireUncapturedIntentsUseCaseOutput struct {
+		ExpiredPaymentIntentIDs []domain.PaymentIntentID
+	}
+
+	ExpireUncapturedIntentsUseCase interface {
+		Execute(context.Context, ExpireUncapturedIntentsUseCaseInput) (*ExpireUncapturedIntentsUseCaseOutput, error)
+	}
+
+	expireUncapturedIntentsUseCase struct {
+		paymentIntentRepository      repository.PaymentIntentRepository
+		paymentIntentQueryRepository repository.PaymentIntentQueryRepository
+		captureDeadlinePolicy        domain.PaymentCaptureDeadlinePolicy
+		clock                        service.Clock
+	}
+)
+
+func NewExpireUncapturedIntentsUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	paymentIntentQueryRepository repository.PaymentIntentQueryRepository,
+	captureDeadlinePolicy domain.PaymentCaptureDeadlinePolicy,
+	clock service.Clock,
+) ExpireUncapturedIntentsUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if paymentIntentQueryRepository == nil {
+		panic("paymentIntentQueryRepository is nil")
+	}
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &expireUncapturedIntentsUseCase{
+		paymentIntentRepository:      paymentIntentRepository,
+		paymentIntentQueryRepository: paymentIntentQueryRepository,
+		captureDeadlinePolicy:        captureDeadlinePolicy,
+		clock:                        clock,
+	}
+}
+
+func (u *expireUncapturedIntentsUseCase) Execute(ctx context.Context, input ExpireUncapturedIntentsUseCaseInput) (*ExpireUncapturedIntentsUseCaseOutput, error) {
+	now := u.clock.Now()
+	expired := make([]domain.PaymentIntentID, 0)
+
+	query := repository.PaymentIntentQuery{
+		BusinessID: input.BusinessID,
+		Statuses:   []domain.PaymentIntentStatus{domain.PaymentIntentStatusRequiresCapture},
+	}
+	for {
+		page, err := u.paymentIntentQueryRepository.List(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, paymentIntent := range page.PaymentIntents {
+			intent, ok := paymentIntent.(domain.PaymentIntentRequiresCapture)
+			if !ok || !intent.IsAuthorizationExpired(u.captureDeadlinePolicy, now) {
+				continue
+			}
+
+			event, aggregate, err := intent.ExpireAuthorization(u.captureDeadlinePolicy, now)
+			if err != nil {
+				return nil, err
+			}
+			if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+				// 他のスイーパーや顧客操作が先に更新した場合は次回の実行に任せる
+				if errors.Is(err, repository.ErrConcurrentModification) {
+					continue
+				}
+				return nil, err
+			}
+			expired = append(expired, intent.ID)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	return &ExpireUncapturedIntentsUseCaseOutput{
+		ExpiredPaymentIntentIDs: expired,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+// conflictingPaymentIntentRepository simulates another writer saving the intent first.
+type conflictingPaymentIntentRepository struct {
+	repository.PaymentIntentRepository
+}
+
+func (conflictingPaymentIntentRepository) Save(context.Context, domain.PaymentIntentEvent, domain.PaymentIntent) error {
+	return repository.ErrConcurrentModification
+}
+
+func TestExpireUncapturedIntentsUseCase_ShouldCancelOverdueAuthorizations(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime)
+	policy := domain.PaymentCaptureDeadlinePolicy{
+		Windows: map[domain.PaymentMethodType]time.Duration{
+			domain.PaymentMethodTypeCard: 48 * time.Hour,
+		},
+	}
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewExpireUncapturedIntentsUseCase(repo, repo, policy, clock)
+
+	clock.Advance(47 * time.Hour)
+	output, err := useCase.Execute(ctx, ExpireUncapturedIntentsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Empty(t, output.ExpiredPaymentIntentIDs)
+
+	clock.Advance(time.Hour)
+	output, err = useCase.Execute(ctx, ExpireUncapturedIntentsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PaymentIntentID{intent.ID}, output.ExpiredPaymentIntentIDs)
+
+	latest, err := repo.FindBy(ctx, intent.ID)
+	require.NoError(t, err)
+	canceled, ok := (*latest).(domain.PaymentIntentCanceled)
+	require.True(t, ok)
+	assert.Equal(t, domain.PaymentCancellationReasonAuthorizationExpired, canceled.CancellationReason)
+}
+
+func TestExpireUncapturedIntentsUseCase_ShouldSucceedWithCapturedPieces(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime)
+	policy := domain.PaymentCaptureDeadlinePolicy{}
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	event, partial, err := intent.CapturePartially(domain.Money(30))
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, partial))
+
+	clock.Advance(policy.WindowFor(domain.PaymentMethodTypeCard))
+	output, err := NewExpireUncapturedIntentsUseCase(repo, repo, policy, clock).Execute(ctx, ExpireUncapturedIntentsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PaymentIntentID{intent.ID}, output.ExpiredPaymentIntentIDs)
+
+	// プロバイダからの payment_succeeded を待たずに、取り込み済みの分で完了させる
+	latest, err := repo.FindBy(ctx, intent.ID)
+	require.NoError(t, err)
+	succeeded, ok := (*latest).(domain.PaymentIntentSucceeded)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(30), succeeded.AmountCaptured)
+	assert.Equal(t, domain.Money(30), succeeded.AmountRefundable())
+}
+
+func TestExpireUncapturedIntentsUseCase_ShouldLeaveConcurrentlyModifiedIntentsToTheNextSweep(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	clock := iasvc.NewFakeClock(seedTime)
+	policy := domain.PaymentCaptureDeadlinePolicy{}
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	clock.Advance(policy.WindowFor(domain.PaymentMethodTypeCard))
+
+	useCase := NewExpireUncapturedIntentsUseCase(conflictingPaymentIntentRepository{repo}, repo, policy, clock)
+	output, err := useCase.Execute(ctx, ExpireUncapturedIntentsUseCaseInput{})
+	require.NoError(t, err)
+	assert.Empty(t, output.ExpiredPaymentIntentIDs)
+
+	latest, err := repo.FindBy(ctx, intent.ID)
+	require.NoError(t, err)
+	assert.IsType(t, domain.PaymentIntentRequiresCapture{}, *latest)
+}
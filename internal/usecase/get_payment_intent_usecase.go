@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+type (
+	GetPaymentIntentUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+	}
+
+	GetPaymentIntentUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	GetPaymentIntentUseCase interface {
+		Execute(context.Context, GetPaymentIntentUseCaseInput) (*GetPaymentIntentUseCaseOutput, error)
+	}
+
+	getPaymentIntentUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+	}
+)
+
+func NewGetPaymentIntentUseCase(paymentIntentRepository repository.PaymentIntentRepository) GetPaymentIntentUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	return &getPaymentIntentUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+	}
+}
+
+func (i GetPaymentIntentUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID)
+}
+
+func (u *getPaymentIntentUseCase) Execute(ctx context.Context, input GetPaymentIntentUseCaseInput) (*GetPaymentIntentUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	return &GetPaymentIntentUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   *paymentIntent,
+	}, nil
+}
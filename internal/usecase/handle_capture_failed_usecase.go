@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	HandleCaptureFailedUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+	}
+
+	HandleCaptureFailedUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	HandleCaptureFailedUseCase interface {
+		Execute(context.Context, HandleCaptureFailedUseCaseInput) (*HandleCaptureFailedUseCaseOutput, error)
+	}
+
+	handleCaptureFailedUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+		clock                   service.Clock
+	}
+)
+
+func NewHandleCaptureFailedUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	clock service.Clock,
+) HandleCaptureFailedUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &handleCaptureFailedUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+		clock:                   clock,
+	}
+}
+
+func (i HandleCaptureFailedUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID)
+}
+
+func (u *handleCaptureFailedUseCase) Execute(ctx context.Context, input HandleCaptureFailedUseCaseInput) (*HandleCaptureFailedUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	intent, ok := (*paymentIntent).(domain.PaymentIntentProcessing)
+	if !ok {
+		// すでに進んでいる場合も成功として返す
+		return &HandleCaptureFailedUseCaseOutput{
+			PaymentIntentID: input.PaymentIntentID,
+			PaymentIntent:   *paymentIntent,
+		}, nil
+	}
+
+	// 確定したキャプチャが後から失敗した場合はオーソリも残っていないので再試行させない。
+	// それ以前の分割キャプチャで確定した分があれば、その分で成功として確定する
+	event, aggregate, err := intent.Fail(domain.PaymentFailureReasonCaptureFailed, false, u.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	return &HandleCaptureFailedUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   aggregate,
+	}, nil
+}
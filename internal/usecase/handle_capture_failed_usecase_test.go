@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+)
+
+func TestHandleCaptureFailedUseCase_ShouldKeepPiecesCapturedBeforeTheFailedFinalCapture(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+
+	event, aggregate, err := intent.CapturePartially(30)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+	event, aggregate, err = aggregate.(domain.PaymentIntentRequiresCapture).CapturePartially(20)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+	event, aggregate, err = aggregate.(domain.PaymentIntentRequiresCapture).Capture(70, domain.PaymentOverCapturePolicy{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+
+	output, err := NewHandleCaptureFailedUseCase(repo, iasvc.NewFakeClock(seedTime)).Execute(ctx, HandleCaptureFailedUseCaseInput{
+		PaymentIntentID: intent.ID,
+	})
+	require.NoError(t, err)
+
+	succeeded, ok := output.PaymentIntent.(domain.PaymentIntentSucceeded)
+	require.True(t, ok, "%T", output.PaymentIntent)
+	assert.Equal(t, domain.Money(50), succeeded.AmountCaptured)
+	assert.Equal(t, domain.Money(50), succeeded.AmountRefundable())
+}
+
+func TestHandleCaptureFailedUseCase_ShouldCancelWhenNothingWasCaptured(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	processing := seedPaymentIntentProcessing(t, ctx, repo, domain.PaymentCaptureMethodManual)
+
+	output, err := NewHandleCaptureFailedUseCase(repo, iasvc.NewFakeClock(seedTime)).Execute(ctx, HandleCaptureFailedUseCaseInput{
+		PaymentIntentID: processing.ID,
+	})
+	require.NoError(t, err)
+
+	// オーソリを使い切っているので再試行させない
+	canceled, ok := output.PaymentIntent.(domain.PaymentIntentCanceled)
+	require.True(t, ok, "%T", output.PaymentIntent)
+	assert.Equal(t, domain.PaymentFailureReasonCaptureFailed, canceled.FailureReason)
+	assert.Equal(t, processing.SeqNr+1, canceled.SeqNr)
+}
+
+func TestHandleCaptureFailedUseCase_ShouldReturnIntentThatAlreadySettled(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	succeeded := seedPaymentIntentSucceeded(t, ctx, repo)
+
+	output, err := NewHandleCaptureFailedUseCase(repo, iasvc.NewFakeClock(seedTime)).Execute(ctx, HandleCaptureFailedUseCaseInput{
+		PaymentIntentID: succeeded.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, succeeded, output.PaymentIntent)
+}
+
+func TestHandleCaptureFailedUseCase_ShouldReportMissingIntent(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	_, err := NewHandleCaptureFailedUseCase(repo, iasvc.NewFakeClock(seedTime)).Execute(ctx, HandleCaptureFailedUseCaseInput{
+		PaymentIntentID: "pi_missing",
+	})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -5,6 +5,7 @@ import (
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
 )
 
 type (
@@ -23,35 +24,42 @@ type (
 
 	handlePaymentActionResultUseCase struct {
 		paymentIntentRepository repository.PaymentIntentRepository
+		clock                   service.Clock
 	}
 )
 
-func NewHandlePaymentActionResultUseCase(paymentIntentRepository repository.PaymentIntentRepository) HandlePaymentActionResultUseCase {
+func NewHandlePaymentActionResultUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	clock service.Clock,
+) HandlePaymentActionResultUseCase {
 	if paymentIntentRepository == nil {
 		panic("paymentIntentRepository is nil")
 	}
+	if clock == nil {
+		panic("clock is nil")
+	}
 	return &handlePaymentActionResultUseCase{
 		paymentIntentRepository: paymentIntentRepository,
+		clock:                   clock,
 	}
 }
 
 func (i HandlePaymentActionResultUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.PaymentIntentID)
-	return nil
+	return contract.Validate(i.PaymentIntentID)
 }
 
 func (u *handlePaymentActionResultUseCase) Execute(ctx context.Context, input HandlePaymentActionResultUseCaseInput) (*HandlePaymentActionResultUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
 	if err != nil {
 		return nil, err
 	}
 	if paymentIntent == nil {
-		// Webhook が先に届いた場合などは黙って終了
-		return &HandlePaymentActionResultUseCaseOutput{
-			PaymentIntentID: input.PaymentIntentID,
-		}, nil
+		// 先に届いた通知は ApplyPaymentIntentEventUseCase が保留するので、ここで黙って捨てない
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
 	}
 
 	switch intent := (*paymentIntent).(type) {
@@ -65,7 +73,7 @@ func (u *handlePaymentActionResultUseCase) Execute(ctx context.Context, input Ha
 		case domain.PaymentCaptureMethodAutomatic:
 			event, aggregate, err = intent.StartProcessing()
 		case domain.PaymentCaptureMethodManual:
-			event, aggregate, err = intent.RequireCapture()
+			event, aggregate, err = intent.RequireCapture(u.clock.Now())
 		default:
 			panic("invalid capture method")
 		}
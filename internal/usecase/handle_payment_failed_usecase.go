@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	HandlePaymentFailedUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+		// Decline is the provider's classification; leave it empty when the provider did not give one.
+		Decline domain.PaymentDecline
+	}
+
+	HandlePaymentFailedUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	HandlePaymentFailedUseCase interface {
+		Execute(context.Context, HandlePaymentFailedUseCaseInput) (*HandlePaymentFailedUseCaseOutput, error)
+	}
+
+	handlePaymentFailedUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+		attemptPolicy           domain.PaymentAttemptPolicy
+		clock                   service.Clock
+	}
+)
+
+func NewHandlePaymentFailedUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	attemptPolicy domain.PaymentAttemptPolicy,
+	clock service.Clock,
+) HandlePaymentFailedUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	contract.AssertValidatable(attemptPolicy)
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &handlePaymentFailedUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+		attemptPolicy:           attemptPolicy,
+		clock:                   clock,
+	}
+}
+
+func (i HandlePaymentFailedUseCaseInput) Validate() error {
+	if i.Decline.Category == "" {
+		return contract.Validate(i.PaymentIntentID)
+	}
+	return contract.Validate(i.PaymentIntentID, i.Decline)
+}
+
+func (u *handlePaymentFailedUseCase) Execute(ctx context.Context, input HandlePaymentFailedUseCaseInput) (*HandlePaymentFailedUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	var (
+		event     domain.PaymentIntentEvent
+		aggregate domain.PaymentIntent
+	)
+	switch intent := (*paymentIntent).(type) {
+	case domain.PaymentIntentRequiresAction:
+		// 3DS などの追加認証に失敗した場合
+		event, aggregate, err = intent.FailPayment(input.Decline, u.clock.Now(), u.attemptPolicy)
+	case domain.PaymentIntentProcessing:
+		event, aggregate, err = intent.FailPayment(input.Decline, u.clock.Now(), u.attemptPolicy)
+	default:
+		// 重複配信などですでに失敗済み・完了済みの場合も成功として返す
+		return &HandlePaymentFailedUseCaseOutput{
+			PaymentIntentID: input.PaymentIntentID,
+			PaymentIntent:   *paymentIntent,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	return &HandlePaymentFailedUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   aggregate,
+	}, nil
+}
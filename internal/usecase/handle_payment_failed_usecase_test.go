@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+)
+
+func TestHandlePaymentFailedUseCase_ShouldFailProcessingIntentByCaptureMethodAndDecline(t *testing.T) {
+	tests := []struct {
+		name               string
+		captureMethod      domain.PaymentCaptureMethod
+		decline            domain.PaymentDecline
+		maxFailedAttempts  uint8
+		wantStatus         domain.PaymentIntentStatus
+		wantReason         domain.PaymentFailureReason
+		wantCancellation   domain.PaymentCancellationReason
+		wantDeclineCode    string
+		wantDeclineMessage string
+	}{
+		{
+			name:          "unclassified failure on automatic capture is retryable",
+			captureMethod: domain.PaymentCaptureMethodAutomatic,
+			wantStatus:    domain.PaymentIntentStatusRequiresPaymentMethod,
+			wantReason:    domain.PaymentFailureReasonPaymentFailed,
+		},
+		{
+			name:          "retryable decline on automatic capture",
+			captureMethod: domain.PaymentCaptureMethodAutomatic,
+			decline: domain.PaymentDecline{
+				Code:            "insufficient_funds",
+				Category:        domain.PaymentDeclineCategoryInsufficientFunds,
+				Retryable:       true,
+				CustomerMessage: "Your account has insufficient funds.",
+			},
+			wantStatus:         domain.PaymentIntentStatusRequiresPaymentMethod,
+			wantReason:         domain.PaymentFailureReasonInsufficientFunds,
+			wantDeclineCode:    "insufficient_funds",
+			wantDeclineMessage: "Your account has insufficient funds.",
+		},
+		{
+			name:          "terminal decline on automatic capture",
+			captureMethod: domain.PaymentCaptureMethodAutomatic,
+			decline: domain.PaymentDecline{
+				Code:     "do_not_honor",
+				Category: domain.PaymentDeclineCategoryDoNotHonor,
+			},
+			wantStatus:      domain.PaymentIntentStatusCanceled,
+			wantReason:      domain.PaymentFailureReasonDoNotHonor,
+			wantDeclineCode: "do_not_honor",
+		},
+		{
+			name:          "manual capture has used up its authorization",
+			captureMethod: domain.PaymentCaptureMethodManual,
+			decline: domain.PaymentDecline{
+				Category:  domain.PaymentDeclineCategoryInsufficientFunds,
+				Retryable: true,
+			},
+			wantStatus: domain.PaymentIntentStatusCanceled,
+			wantReason: domain.PaymentFailureReasonInsufficientFunds,
+		},
+		{
+			name:              "retryable decline after the last allowed attempt",
+			captureMethod:     domain.PaymentCaptureMethodAutomatic,
+			maxFailedAttempts: 1,
+			wantStatus:        domain.PaymentIntentStatusCanceled,
+			wantCancellation:  domain.PaymentCancellationReasonMaxAttemptsExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := iarepo.NewInMemoryPaymentIntentRepository()
+			processing := seedPaymentIntentProcessing(t, ctx, repo, tt.captureMethod)
+			useCase := NewHandlePaymentFailedUseCase(
+				repo,
+				domain.PaymentAttemptPolicy{MaxFailedAttempts: tt.maxFailedAttempts},
+				iasvc.NewFakeClock(seedTime),
+			)
+
+			output, err := useCase.Execute(ctx, HandlePaymentFailedUseCaseInput{
+				PaymentIntentID: processing.ID,
+				Decline:         tt.decline,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, output.PaymentIntent.Status())
+
+			var attempts domain.PaymentAttempts
+			switch intent := output.PaymentIntent.(type) {
+			case domain.PaymentIntentRequiresPaymentMethod:
+				assert.Equal(t, tt.wantReason, intent.FailureReason)
+				attempts = intent.Attempts
+			case domain.PaymentIntentCanceled:
+				if tt.wantCancellation != "" {
+					assert.Equal(t, tt.wantCancellation, intent.CancellationReason)
+				} else {
+					assert.Equal(t, tt.wantReason, intent.FailureReason)
+				}
+				attempts = intent.Attempts
+			}
+			require.NotEmpty(t, attempts)
+			last := attempts[len(attempts)-1]
+			assert.Equal(t, domain.PaymentAttemptOutcomeFailed, last.Outcome)
+			assert.Equal(t, tt.wantDeclineCode, last.ProviderErrorCode)
+			assert.Equal(t, tt.wantDeclineMessage, last.CustomerMessage)
+		})
+	}
+}
+
+func TestHandlePaymentFailedUseCase_ShouldKeepPiecesCapturedBeforeTheFailedFinalCapture(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+
+	event, aggregate, err := intent.CapturePartially(40)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+	event, aggregate, err = aggregate.(domain.PaymentIntentRequiresCapture).Capture(80, domain.PaymentOverCapturePolicy{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+
+	useCase := NewHandlePaymentFailedUseCase(repo, domain.PaymentAttemptPolicy{MaxFailedAttempts: 3}, iasvc.NewFakeClock(seedTime))
+	output, err := useCase.Execute(ctx, HandlePaymentFailedUseCaseInput{
+		PaymentIntentID: intent.ID,
+		Decline:         domain.PaymentDecline{Category: domain.PaymentDeclineCategoryInsufficientFunds},
+	})
+	require.NoError(t, err)
+
+	succeeded, ok := output.PaymentIntent.(domain.PaymentIntentSucceeded)
+	require.True(t, ok, "%T", output.PaymentIntent)
+	assert.Equal(t, domain.Money(40), succeeded.AmountCaptured)
+}
+
+func TestHandlePaymentFailedUseCase_ShouldIgnoreDuplicateDelivery(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	processing := seedPaymentIntentProcessing(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	useCase := NewHandlePaymentFailedUseCase(repo, domain.PaymentAttemptPolicy{MaxFailedAttempts: 3}, iasvc.NewFakeClock(seedTime))
+	input := HandlePaymentFailedUseCaseInput{PaymentIntentID: processing.ID}
+
+	first, err := useCase.Execute(ctx, input)
+	require.NoError(t, err)
+	events := len(repo.Events())
+
+	second, err := useCase.Execute(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, first.PaymentIntent, second.PaymentIntent)
+	assert.Len(t, repo.Events(), events)
+}
+
+func TestHandlePaymentFailedUseCase_ShouldRejectUnknownDeclineCategory(t *testing.T) {
+	useCase := NewHandlePaymentFailedUseCase(iarepo.NewInMemoryPaymentIntentRepository(), domain.PaymentAttemptPolicy{}, iasvc.NewFakeClock(seedTime))
+
+	_, err := useCase.Execute(context.Background(), HandlePaymentFailedUseCaseInput{
+		PaymentIntentID: "pi_1",
+		Decline:         domain.PaymentDecline{Category: "lost_card"},
+	})
+	require.Error(t, err)
+}
+
+func seedPaymentIntentProcessing(
+	t *testing.T,
+	ctx context.Context,
+	repo *iarepo.InMemoryPaymentIntentRepository,
+	captureMethod domain.PaymentCaptureMethod,
+) domain.PaymentIntentProcessing {
+	t.Helper()
+
+	if captureMethod == domain.PaymentCaptureMethodManual {
+		capture := seedPaymentIntentRequiresCapture(t, ctx, repo)
+		event, aggregate, err := capture.Capture(capture.Amount, domain.PaymentOverCapturePolicy{})
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(ctx, event, aggregate))
+		return aggregate.(domain.PaymentIntentProcessing)
+	}
+
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, captureMethod)
+	event, aggregate, err := confirmation.StartProcessing()
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+	return aggregate.(domain.PaymentIntentProcessing)
+}
@@ -37,26 +37,27 @@ func NewHandlePaymentSucceededUseCase(paymentIntentRepository repository.Payment
 }
 
 func (i HandlePaymentSucceededUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.PaymentIntentID)
-	return nil
+	return contract.Validate(i.PaymentIntentID)
 }
 
 func (u *handlePaymentSucceededUseCase) Execute(ctx context.Context, input HandlePaymentSucceededUseCaseInput) (*HandlePaymentSucceededUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
 	if err != nil {
 		return nil, err
 	}
 	if paymentIntent == nil {
-		// Webhookが先に届いた場合は黙って成功
-		return &HandlePaymentSucceededUseCaseOutput{
-			PaymentIntentID: input.PaymentIntentID,
-		}, nil
+		// 先に届いた通知は ApplyPaymentIntentEventUseCase が保留するので、ここで黙って捨てない
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
 	}
 
 	intent, ok := (*paymentIntent).(domain.PaymentIntentProcessing)
 	if !ok {
+		// 分割キャプチャの途中 (requires_capture) に届いた個々の決済完了は無視し、
+		// 最終キャプチャで processing になった後の通知で succeeded に遷移させる
 		// すでに進んでいても成功として返す
 		return &HandlePaymentSucceededUseCaseOutput{
 			PaymentIntentID: input.PaymentIntentID,
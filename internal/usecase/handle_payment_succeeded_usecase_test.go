@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+)
+
+func TestHandlePaymentSucceededUseCase_ShouldReportMissingIntent(t *testing.T) {
+	_, err := NewHandlePaymentSucceededUseCase(iarepo.NewInMemoryPaymentIntentRepository()).Execute(
+		context.Background(),
+		HandlePaymentSucceededUseCaseInput{PaymentIntentID: "pi_missing"},
+	)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
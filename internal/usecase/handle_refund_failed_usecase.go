@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+type (
+	HandleRefundFailedUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+		RefundID        domain.PaymentRefundID
+	}
+
+	HandleRefundFailedUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	HandleRefundFailedUseCase interface {
+		Execute(context.Context, HandleRefundFailedUseCaseInput) (*HandleRefundFailedUseCaseOutput, error)
+	}
+
+	handleRefundFailedUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+	}
+)
+
+func NewHandleRefundFailedUseCase(paymentIntentRepository repository.PaymentIntentRepository) HandleRefundFailedUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	return &handleRefundFailedUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+	}
+}
+
+func (i HandleRefundFailedUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID, i.RefundID)
+}
+
+func (u *handleRefundFailedUseCase) Execute(ctx context.Context, input HandleRefundFailedUseCaseInput) (*HandleRefundFailedUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	intent, ok := (*paymentIntent).(domain.PaymentIntentSucceeded)
+	if !ok {
+		return &HandleRefundFailedUseCaseOutput{
+			PaymentIntentID: input.PaymentIntentID,
+			PaymentIntent:   *paymentIntent,
+		}, nil
+	}
+	if refund, _, found := intent.Refunds.Find(input.RefundID); found && refund.Status != domain.PaymentRefundStatusPending {
+		// 重複した通知などですでに確定している
+		return &HandleRefundFailedUseCaseOutput{
+			PaymentIntentID: input.PaymentIntentID,
+			PaymentIntent:   intent,
+		}, nil
+	}
+
+	event, aggregate, err := intent.FailRefund(input.RefundID, domain.PaymentFailureReasonRefundFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	return &HandleRefundFailedUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   aggregate,
+	}, nil
+}
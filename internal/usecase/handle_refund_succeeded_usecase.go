@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+type (
+	HandleRefundSucceededUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+		RefundID        domain.PaymentRefundID
+	}
+
+	HandleRefundSucceededUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	HandleRefundSucceededUseCase interface {
+		Execute(context.Context, HandleRefundSucceededUseCaseInput) (*HandleRefundSucceededUseCaseOutput, error)
+	}
+
+	handleRefundSucceededUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+	}
+)
+
+func NewHandleRefundSucceededUseCase(paymentIntentRepository repository.PaymentIntentRepository) HandleRefundSucceededUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	return &handleRefundSucceededUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+	}
+}
+
+func (i HandleRefundSucceededUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID, i.RefundID)
+}
+
+func (u *handleRefundSucceededUseCase) Execute(ctx context.Context, input HandleRefundSucceededUseCaseInput) (*HandleRefundSucceededUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	intent, ok := (*paymentIntent).(domain.PaymentIntentSucceeded)
+	if !ok {
+		return &HandleRefundSucceededUseCaseOutput{
+			PaymentIntentID: input.PaymentIntentID,
+			PaymentIntent:   *paymentIntent,
+		}, nil
+	}
+	if refund, _, found := intent.Refunds.Find(input.RefundID); found && refund.Status != domain.PaymentRefundStatusPending {
+		// 重複した通知などですでに確定している
+		return &HandleRefundSucceededUseCaseOutput{
+			PaymentIntentID: input.PaymentIntentID,
+			PaymentIntent:   intent,
+		}, nil
+	}
+
+	event, aggregate, err := intent.SucceedRefund(input.RefundID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	return &HandleRefundSucceededUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   aggregate,
+	}, nil
+}
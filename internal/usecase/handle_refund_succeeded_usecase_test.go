@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+)
+
+func TestHandleRefundSucceededUseCase_ShouldSettlePendingRefund(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentRefundPending(t, ctx, repo, "re_1", 20)
+	useCase := NewHandleRefundSucceededUseCase(repo)
+
+	output, err := useCase.Execute(ctx, HandleRefundSucceededUseCaseInput{
+		PaymentIntentID: intent.ID,
+		RefundID:        "re_1",
+	})
+	require.NoError(t, err)
+
+	result := output.PaymentIntent.(domain.PaymentIntentSucceeded)
+	require.Len(t, result.Refunds, 1)
+	assert.Equal(t, domain.PaymentRefundStatusSucceeded, result.Refunds[0].Status)
+	assert.Equal(t, domain.Money(20), result.Refunds.AmountRefunded())
+	assert.Equal(t, intent.SeqNr+1, result.SeqNr)
+}
+
+func TestHandleRefundSucceededUseCase_ShouldIgnoreDuplicateNotification(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentRefundPending(t, ctx, repo, "re_1", 20)
+	useCase := NewHandleRefundSucceededUseCase(repo)
+	input := HandleRefundSucceededUseCaseInput{PaymentIntentID: intent.ID, RefundID: "re_1"}
+
+	first, err := useCase.Execute(ctx, input)
+	require.NoError(t, err)
+	second, err := useCase.Execute(ctx, input)
+	require.NoError(t, err)
+
+	// 二度目は保存せずに現在の状態を返す
+	assert.Equal(t, first.PaymentIntent, second.PaymentIntent)
+	stored, err := repo.FindBy(ctx, intent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, intent.SeqNr+1, (*stored).(domain.PaymentIntentSucceeded).SeqNr)
+}
+
+func TestHandleRefundSucceededUseCase_ShouldNotOverrideFailedRefund(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentRefundPending(t, ctx, repo, "re_1", 20)
+
+	_, err := NewHandleRefundFailedUseCase(repo).Execute(ctx, HandleRefundFailedUseCaseInput{
+		PaymentIntentID: intent.ID,
+		RefundID:        "re_1",
+	})
+	require.NoError(t, err)
+
+	output, err := NewHandleRefundSucceededUseCase(repo).Execute(ctx, HandleRefundSucceededUseCaseInput{
+		PaymentIntentID: intent.ID,
+		RefundID:        "re_1",
+	})
+	require.NoError(t, err)
+
+	result := output.PaymentIntent.(domain.PaymentIntentSucceeded)
+	assert.Equal(t, domain.PaymentRefundStatusFailed, result.Refunds[0].Status)
+	assert.Equal(t, domain.Money(0), result.Refunds.AmountRefunded())
+}
+
+func TestHandleRefundSucceededUseCase_ShouldReportUnknownRefund(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentRefundPending(t, ctx, repo, "re_1", 20)
+
+	_, err := NewHandleRefundSucceededUseCase(repo).Execute(ctx, HandleRefundSucceededUseCaseInput{
+		PaymentIntentID: intent.ID,
+		RefundID:        "re_unknown",
+	})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestHandleRefundSucceededUseCase_ShouldReportMissingIntent(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	_, err := NewHandleRefundSucceededUseCase(repo).Execute(ctx, HandleRefundSucceededUseCaseInput{
+		PaymentIntentID: "pi_missing",
+		RefundID:        "re_1",
+	})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestHandleRefundSucceededUseCase_ShouldLeaveIntentsThatCannotBeRefunded(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	processing := seedPaymentIntentProcessing(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+
+	output, err := NewHandleRefundSucceededUseCase(repo).Execute(ctx, HandleRefundSucceededUseCaseInput{
+		PaymentIntentID: processing.ID,
+		RefundID:        "re_1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, processing, output.PaymentIntent)
+}
+
+func seedPaymentIntentRefundPending(
+	t *testing.T,
+	ctx context.Context,
+	repo *iarepo.InMemoryPaymentIntentRepository,
+	refundID domain.PaymentRefundID,
+	amount domain.Money,
+) domain.PaymentIntentSucceeded {
+	t.Helper()
+
+	succeeded := seedPaymentIntentSucceeded(t, ctx, repo)
+	event, aggregate, err := succeeded.RequestRefund(refundID, amount)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+
+	return aggregate.(domain.PaymentIntentSucceeded)
+}
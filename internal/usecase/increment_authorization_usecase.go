@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	IncrementAuthorizationUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+		IncrementAmount domain.Money
+	}
+
+	IncrementAuthorizationUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	IncrementAuthorizationUseCase interface {
+		Execute(context.Context, IncrementAuthorizationUseCaseInput) (*IncrementAuthorizationUseCaseOutput, error)
+	}
+
+	incrementAuthorizationUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+		paymentProviders        service.PaymentMethodProviderRegistry
+		captureDeadlinePolicy   domain.PaymentCaptureDeadlinePolicy
+		clock                   service.Clock
+	}
+)
+
+func NewIncrementAuthorizationUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	paymentProviders service.PaymentMethodProviderRegistry,
+	captureDeadlinePolicy domain.PaymentCaptureDeadlinePolicy,
+	clock service.Clock,
+) IncrementAuthorizationUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if paymentProviders == nil {
+		panic("paymentProviders is nil")
+	}
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &incrementAuthorizationUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+		paymentProviders:        paymentProviders,
+		captureDeadlinePolicy:   captureDeadlinePolicy,
+		clock:                   clock,
+	}
+}
+
+func (i IncrementAuthorizationUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID, i.IncrementAmount)
+}
+
+func (u *incrementAuthorizationUseCase) Execute(ctx context.Context, input IncrementAuthorizationUseCaseInput) (*IncrementAuthorizationUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	intent, ok := (*paymentIntent).(domain.PaymentIntentRequiresCapture)
+	if !ok {
+		return nil, fmt.Errorf("%w: payment intent is not in requires_capture state", domain.ErrInvalidStateTransition)
+	}
+	// 期限切れのオーソリはプロバイダ側でも増額できないので、依頼する前に弾く
+	if intent.IsAuthorizationExpired(u.captureDeadlinePolicy, u.clock.Now()) {
+		return nil, fmt.Errorf("%w: payment intent authorization has expired", domain.ErrInvalidStateTransition)
+	}
+
+	paymentProvider, err := u.paymentProviders.ProviderFor(intent.BusinessID, intent.PaymentMethod.PaymentMethodType)
+	if err != nil {
+		return nil, err
+	}
+
+	// プロバイダに依頼する前に上限チェックを済ませておく
+	event, aggregate, err := intent.IncrementAuthorization(input.IncrementAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	// 増額に失敗しても元のオーソリは有効なので状態は変えない
+	if err := paymentProvider.IncrementAuthorization(ctx, service.PaymentIncrementAuthorizationRequest{
+		Intent:          intent,
+		Amount:          intent.Amount,
+		IncrementAmount: input.IncrementAmount,
+	}); err != nil {
+		return nil, fmt.Errorf("increment authorization failed: %w", err)
+	}
+
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	return &IncrementAuthorizationUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   aggregate,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+)
+
+func TestIncrementAuthorizationUseCase_ShouldRaiseAuthorizedAmount(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewIncrementAuthorizationUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}), domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+
+	output, err := useCase.Execute(ctx, IncrementAuthorizationUseCaseInput{
+		PaymentIntentID: intent.ID,
+		IncrementAmount: 30,
+	})
+	require.NoError(t, err)
+
+	result, ok := output.PaymentIntent.(domain.PaymentIntentRequiresCapture)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(150), result.Amount)
+	assert.Equal(t, domain.Money(150), result.AmountCapturable())
+
+	events := repo.Events()
+	incremented, ok := events[len(events)-1].(domain.PaymentIntentAmountIncrementedEvent)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(120), incremented.PreviousAmount)
+	assert.Equal(t, domain.Money(30), incremented.IncrementAmount)
+	assert.Equal(t, domain.Money(150), incremented.Amount)
+}
+
+func TestIncrementAuthorizationUseCase_ShouldKeepOriginalAuthorizationOnFailure(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		provider *fakePaymentMethodProvider
+		amount   domain.Money
+	}{
+		{name: "provider error", provider: &fakePaymentMethodProvider{incrementErr: errors.New("provider down")}, amount: 30},
+		{name: "money overflow", provider: &fakePaymentMethodProvider{}, amount: domain.Money(math.MaxUint64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := iarepo.NewInMemoryPaymentIntentRepository()
+			intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+			before := len(repo.Events())
+
+			useCase := NewIncrementAuthorizationUseCase(repo, paymentProvidersOf(tt.provider), domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+			_, err := useCase.Execute(ctx, IncrementAuthorizationUseCaseInput{
+				PaymentIntentID: intent.ID,
+				IncrementAmount: tt.amount,
+			})
+			require.Error(t, err)
+			assert.Len(t, repo.Events(), before)
+
+			latest, err := repo.FindBy(ctx, intent.ID)
+			require.NoError(t, err)
+			result, ok := (*latest).(domain.PaymentIntentRequiresCapture)
+			require.True(t, ok)
+			assert.Equal(t, intent.Amount, result.Amount)
+		})
+	}
+}
+
+func TestIncrementAuthorizationUseCase_ShouldRejectExpiredAuthorization(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	provider := &fakePaymentMethodProvider{}
+
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	before := len(repo.Events())
+	useCase := NewIncrementAuthorizationUseCase(
+		repo,
+		paymentProvidersOf(provider),
+		domain.PaymentCaptureDeadlinePolicy{},
+		iasvc.NewFakeClock(seedTime.Add(domain.DefaultPaymentCaptureWindow)),
+	)
+
+	_, err := useCase.Execute(ctx, IncrementAuthorizationUseCaseInput{
+		PaymentIntentID: intent.ID,
+		IncrementAmount: 30,
+	})
+
+	require.ErrorIs(t, err, domain.ErrInvalidStateTransition)
+	assert.Zero(t, provider.incremented)
+	assert.Len(t, repo.Events(), before)
+}
+
+func TestIncrementAuthorizationUseCase_ShouldKeepCountingSeqNrPastTheOldLimit(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	intent := seedPaymentIntentRequiresCapture(t, ctx, repo)
+	useCase := NewIncrementAuthorizationUseCase(repo, paymentProvidersOf(&fakePaymentMethodProvider{}), domain.PaymentCaptureDeadlinePolicy{}, iasvc.NewFakeClock(seedTime))
+
+	// 1 バイトの上限 255 を越えても SeqNr が巻き戻らず、楽観ロックも効き続ける
+	var current domain.PaymentIntent = intent
+	for current.(domain.PaymentIntentRequiresCapture).SeqNr <= math.MaxUint8 {
+		output, err := useCase.Execute(ctx, IncrementAuthorizationUseCaseInput{
+			PaymentIntentID: intent.ID,
+			IncrementAmount: 1,
+		})
+		require.NoError(t, err)
+		current = output.PaymentIntent
+	}
+
+	latest := current.(domain.PaymentIntentRequiresCapture)
+	assert.Equal(t, uint64(math.MaxUint8+1), latest.SeqNr)
+	assert.Len(t, repo.Events(), math.MaxUint8+1)
+
+	stale := latest
+	stale.SeqNr--
+	event, aggregate, err := stale.IncrementAuthorization(1)
+	require.NoError(t, err)
+	require.ErrorIs(t, repo.Save(ctx, event, aggregate), domain.ErrConflict)
+}
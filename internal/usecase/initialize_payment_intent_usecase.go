@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
@@ -30,6 +29,7 @@ type (
 		paymentIntentRepository repository.PaymentIntentRepository
 		paymentIntentGenerator  service.PaymentIDGenerator
 		businessRepository      repository.BusinessRepository
+		clock                   service.Clock
 	}
 )
 
@@ -38,6 +38,7 @@ func NewInitializePaymentIntentUseCase(
 	paymentIntentRepository repository.PaymentIntentRepository,
 	paymentIntentGenerator service.PaymentIDGenerator,
 	businessRepository repository.BusinessRepository,
+	clock service.Clock,
 ) InitializePaymentIntentUseCase {
 	if tokenService == nil {
 		panic("tokenService is nil")
@@ -51,21 +52,26 @@ func NewInitializePaymentIntentUseCase(
 	if businessRepository == nil {
 		panic("businessRepository is nil")
 	}
+	if clock == nil {
+		panic("clock is nil")
+	}
 	return &initializePaymentIntentUseCase{
 		tokenService:            tokenService,
 		paymentIntentRepository: paymentIntentRepository,
 		paymentIntentGenerator:  paymentIntentGenerator,
 		businessRepository:      businessRepository,
+		clock:                   clock,
 	}
 }
 
 func (i InitializePaymentIntentUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.CartToken)
-	return nil
+	return contract.Validate(i.CartToken)
 }
 
 func (u *initializePaymentIntentUseCase) Execute(ctx context.Context, input InitializePaymentIntentUseCaseInput) (*InitializePaymentIntentUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	cart, err := u.tokenService.ParseCartToken(ctx, input.CartToken)
 	if err != nil {
@@ -77,7 +83,7 @@ func (u *initializePaymentIntentUseCase) Execute(ctx context.Context, input Init
 		return nil, err
 	}
 	if business == nil {
-		return nil, errors.New("business not found")
+		return nil, domain.NewBusinessNotFoundError(cart.BusinessID)
 	}
 
 	paymentIntentID, err := u.paymentIntentGenerator.GenerateID(ctx)
@@ -85,11 +91,17 @@ func (u *initializePaymentIntentUseCase) Execute(ctx context.Context, input Init
 		return nil, err
 	}
 
-	event, aggregate, err := domain.GeneratePaymentIntent(paymentIntentID, business.PaymentMethodTypes, cart.CalculateAmount())
+	event, aggregate, err := domain.GeneratePaymentIntent(
+		paymentIntentID,
+		cart,
+		business.PaymentMethodTypes,
+		u.clock.Now(),
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	// 同じカートの有効なインテントは一つだけ。同時に作られても Save が後着を ErrConflict で弾く
 	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
 		return nil, err
 	}
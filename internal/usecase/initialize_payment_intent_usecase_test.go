@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+func TestInitializePaymentIntentUseCase_ShouldLinkBusinessAndCart(t *testing.T) {
+	ctx := context.Background()
+	businessRepo := iarepo.NewInMemoryBusinessRepository()
+	paymentIntentRepo := iarepo.NewInMemoryPaymentIntentRepository()
+	tokenService := iasvc.NewTokenService([]byte("cart_token_secret"))
+	cart := seedBusinessAndCart(t, ctx, businessRepo)
+
+	token, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{Cart: cart})
+	require.NoError(t, err)
+
+	useCase := NewInitializePaymentIntentUseCase(
+		tokenService,
+		paymentIntentRepo,
+		iasvc.NewFakePaymentIntentIDGenerator("pi_123"),
+		businessRepo,
+		iasvc.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+	)
+
+	output, err := useCase.Execute(ctx, InitializePaymentIntentUseCaseInput{CartToken: token})
+	require.NoError(t, err)
+
+	intent, ok := output.PaymentIntent.(domain.PaymentIntentRequiresPaymentMethodType)
+	require.True(t, ok)
+	assert.Equal(t, cart.BusinessID, intent.BusinessID)
+	assert.Equal(t, cart.CartID, intent.CartID)
+	assert.Equal(t, cart.Items, intent.CartItems)
+
+	event, ok := paymentIntentRepo.Events()[0].(domain.PaymentIntentRequiresPaymentMethodTypeEvent)
+	require.True(t, ok)
+	assert.Equal(t, cart.BusinessID, event.BusinessID)
+	assert.Equal(t, cart.CartID, event.CartID)
+	assert.Equal(t, cart.Items, event.CartItems)
+}
+
+func TestInitializePaymentIntentUseCase_ShouldRejectSecondActiveIntentForCart(t *testing.T) {
+	ctx := context.Background()
+	businessRepo := iarepo.NewInMemoryBusinessRepository()
+	paymentIntentRepo := iarepo.NewInMemoryPaymentIntentRepository()
+	tokenService := iasvc.NewTokenService([]byte("cart_token_secret"))
+	idGenerator := &iasvc.FakePaymentIntentIDGenerator{NextID: "pi_1"}
+	cart := seedBusinessAndCart(t, ctx, businessRepo)
+
+	token, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{Cart: cart})
+	require.NoError(t, err)
+
+	useCase := NewInitializePaymentIntentUseCase(
+		tokenService,
+		paymentIntentRepo,
+		idGenerator,
+		businessRepo,
+		iasvc.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+	)
+
+	_, err = useCase.Execute(ctx, InitializePaymentIntentUseCaseInput{CartToken: token})
+	require.NoError(t, err)
+
+	idGenerator.NextID = "pi_2"
+	output, err := useCase.Execute(ctx, InitializePaymentIntentUseCaseInput{CartToken: token})
+	require.ErrorIs(t, err, domain.ErrConflict)
+	assert.Nil(t, output)
+	assert.Len(t, paymentIntentRepo.Events(), 1)
+}
+
+func TestInitializePaymentIntentUseCase_ShouldCreateOneActiveIntentForConcurrentRequests(t *testing.T) {
+	ctx := context.Background()
+	businessRepo := iarepo.NewInMemoryBusinessRepository()
+	paymentIntentRepo := iarepo.NewInMemoryPaymentIntentRepository()
+	tokenService := iasvc.NewTokenService([]byte("cart_token_secret"))
+	cart := seedBusinessAndCart(t, ctx, businessRepo)
+
+	token, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{Cart: cart})
+	require.NoError(t, err)
+
+	useCase := NewInitializePaymentIntentUseCase(
+		tokenService,
+		paymentIntentRepo,
+		iasvc.NewRandomPaymentIntentIDGenerator(),
+		businessRepo,
+		iasvc.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+	)
+
+	const requests = 8
+	errs := make([]error, requests)
+	var wg sync.WaitGroup
+	for idx := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[idx] = useCase.Execute(ctx, InitializePaymentIntentUseCaseInput{CartToken: token})
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Len(t, paymentIntentRepo.Events(), 1)
+}
+
+func seedBusinessAndCart(
+	t *testing.T,
+	ctx context.Context,
+	businessRepo *iarepo.InMemoryBusinessRepository,
+) domain.Cart {
+	t.Helper()
+
+	businessID := domain.BusinessID("biz_123")
+	paymentMethodTypes := domain.PaymentMethodTypes{domain.PaymentMethodTypeCard}
+	require.NoError(t, businessRepo.Save(
+		ctx,
+		domain.NewBusinessInitializedEvent(businessID, 1, "Test Business", paymentMethodTypes),
+		domain.Business{ID: businessID, Name: "Test Business", PaymentMethodTypes: paymentMethodTypes},
+	))
+
+	return domain.Cart{
+		BusinessID: businessID,
+		CartID:     "cart_123",
+		Items:      domain.CartItems{{ItemID: "item_123", Price: 120}},
+	}
+}
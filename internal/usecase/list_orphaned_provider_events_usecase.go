@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	ListOrphanedProviderEventsUseCaseInput struct{}
+
+	ListOrphanedProviderEventsUseCaseOutput struct {
+		// Events are the parked events past the inbox TTL, oldest first.
+		Events []domain.PendingProviderEvent
+	}
+
+	ListOrphanedProviderEventsUseCase interface {
+		Execute(context.Context, ListOrphanedProviderEventsUseCaseInput) (*ListOrphanedProviderEventsUseCaseOutput, error)
+	}
+
+	listOrphanedProviderEventsUseCase struct {
+		pendingProviderEventRepository repository.PendingProviderEventRepository
+		inboxPolicy                    domain.ProviderEventInboxPolicy
+		clock                          service.Clock
+	}
+)
+
+func NewListOrphanedProviderEventsUseCase(
+	pendingProviderEventRepository repository.PendingProviderEventRepository,
+	inboxPolicy domain.ProviderEventInboxPolicy,
+	clock service.Clock,
+) ListOrphanedProviderEventsUseCase {
+	if pendingProviderEventRepository == nil {
+		panic("pendingProviderEventRepository is nil")
+	}
+	contract.AssertValidatable(inboxPolicy)
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &listOrphanedProviderEventsUseCase{
+		pendingProviderEventRepository: pendingProviderEventRepository,
+		inboxPolicy:                    inboxPolicy,
+		clock:                          clock,
+	}
+}
+
+func (u *listOrphanedProviderEventsUseCase) Execute(ctx context.Context, input ListOrphanedProviderEventsUseCaseInput) (*ListOrphanedProviderEventsUseCaseOutput, error) {
+	pending, err := u.pendingProviderEventRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.clock.Now()
+	output := &ListOrphanedProviderEventsUseCaseOutput{Events: []domain.PendingProviderEvent{}}
+	for _, parked := range pending {
+		if u.inboxPolicy.IsOrphaned(parked, now) {
+			output.Events = append(output.Events, parked)
+		}
+	}
+	return output, nil
+}
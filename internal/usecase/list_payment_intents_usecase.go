@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/converter"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+const maxListPaymentIntentsLimit = 100
+
+type (
+	ListPaymentIntentsUseCaseInput struct {
+		BusinessID        domain.BusinessID
+		Statuses          []domain.PaymentIntentStatus
+		PaymentMethodType domain.PaymentMethodType
+		CreatedFrom       time.Time
+		CreatedTo         time.Time
+		Cursor            repository.PaymentIntentCursor
+		Limit             int
+	}
+
+	ListPaymentIntentsUseCaseOutput struct {
+		PaymentIntents []converter.PaymentIntentView
+		NextCursor     repository.PaymentIntentCursor
+	}
+
+	ListPaymentIntentsUseCase interface {
+		Execute(context.Context, ListPaymentIntentsUseCaseInput) (*ListPaymentIntentsUseCaseOutput, error)
+	}
+
+	listPaymentIntentsUseCase struct {
+		paymentIntentQueryRepository repository.PaymentIntentQueryRepository
+	}
+)
+
+func NewListPaymentIntentsUseCase(paymentIntentQueryRepository repository.PaymentIntentQueryRepository) ListPaymentIntentsUseCase {
+	if paymentIntentQueryRepository == nil {
+		panic("paymentIntentQueryRepository is nil")
+	}
+	return &listPaymentIntentsUseCase{
+		paymentIntentQueryRepository: paymentIntentQueryRepository,
+	}
+}
+
+func (i ListPaymentIntentsUseCaseInput) Validate() error {
+	if i.BusinessID != "" {
+		if err := contract.Validate(i.BusinessID); err != nil {
+			return err
+		}
+	}
+	for _, status := range i.Statuses {
+		if err := contract.Validate(status); err != nil {
+			return err
+		}
+	}
+	if i.Limit < 0 || i.Limit > maxListPaymentIntentsLimit {
+		return fmt.Errorf("%w: limit is out of range", domain.ErrInvalidArgument)
+	}
+	if !i.CreatedFrom.IsZero() && !i.CreatedTo.IsZero() && !i.CreatedFrom.Before(i.CreatedTo) {
+		return fmt.Errorf("%w: created from must be before created to", domain.ErrInvalidArgument)
+	}
+	return nil
+}
+
+func (u *listPaymentIntentsUseCase) Execute(ctx context.Context, input ListPaymentIntentsUseCaseInput) (*ListPaymentIntentsUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	page, err := u.paymentIntentQueryRepository.List(ctx, repository.PaymentIntentQuery{
+		BusinessID:        input.BusinessID,
+		Statuses:          input.Statuses,
+		PaymentMethodType: input.PaymentMethodType,
+		CreatedFrom:       input.CreatedFrom,
+		CreatedTo:         input.CreatedTo,
+		Cursor:            input.Cursor,
+		Limit:             input.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]converter.PaymentIntentView, 0, len(page.PaymentIntents))
+	for _, intent := range page.PaymentIntents {
+		view, err := converter.ToPaymentIntentView(intent)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	return &ListPaymentIntentsUseCaseOutput{
+		PaymentIntents: views,
+		NextCursor:     page.NextCursor,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+func TestListPaymentIntentsUseCase_ShouldFilterByBusinessStatusAndCreatedAt(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	seedGeneratedPaymentIntent(t, ctx, repo, "pi_1", "biz_a", base)
+	seedGeneratedPaymentIntent(t, ctx, repo, "pi_2", "biz_a", base.Add(time.Hour))
+	seedGeneratedPaymentIntent(t, ctx, repo, "pi_3", "biz_b", base.Add(2*time.Hour))
+	selected := seedGeneratedPaymentIntent(t, ctx, repo, "pi_4", "biz_a", base.Add(3*time.Hour))
+	event, aggregate, err := selected.RequirePaymentMethod(domain.PaymentMethodTypeCard, seedTime)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+
+	useCase := NewListPaymentIntentsUseCase(repo)
+
+	output, err := useCase.Execute(ctx, ListPaymentIntentsUseCaseInput{
+		BusinessID: "biz_a",
+		Statuses:   []domain.PaymentIntentStatus{domain.PaymentIntentStatusRequiresPaymentMethodType},
+	})
+	require.NoError(t, err)
+	require.Len(t, output.PaymentIntents, 2)
+	assert.Equal(t, domain.PaymentIntentID("pi_1"), output.PaymentIntents[0].ID)
+	assert.Equal(t, domain.PaymentIntentID("pi_2"), output.PaymentIntents[1].ID)
+	assert.Empty(t, output.NextCursor)
+
+	output, err = useCase.Execute(ctx, ListPaymentIntentsUseCaseInput{
+		PaymentMethodType: domain.PaymentMethodTypeCard,
+		Statuses:          []domain.PaymentIntentStatus{domain.PaymentIntentStatusRequiresPaymentMethod},
+	})
+	require.NoError(t, err)
+	require.Len(t, output.PaymentIntents, 1)
+	assert.Equal(t, domain.PaymentIntentID("pi_4"), output.PaymentIntents[0].ID)
+
+	output, err = useCase.Execute(ctx, ListPaymentIntentsUseCaseInput{
+		CreatedFrom: base.Add(time.Hour),
+		CreatedTo:   base.Add(3 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.PaymentIntents, 2)
+	assert.Equal(t, domain.PaymentIntentID("pi_2"), output.PaymentIntents[0].ID)
+	assert.Equal(t, domain.PaymentIntentID("pi_3"), output.PaymentIntents[1].ID)
+}
+
+func TestListPaymentIntentsUseCase_ShouldPaginateWithCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for idx, id := range []domain.PaymentIntentID{"pi_1", "pi_2", "pi_3"} {
+		seedGeneratedPaymentIntent(t, ctx, repo, id, "biz_a", base.Add(time.Duration(idx)*time.Minute))
+	}
+
+	useCase := NewListPaymentIntentsUseCase(repo)
+
+	first, err := useCase.Execute(ctx, ListPaymentIntentsUseCaseInput{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.PaymentIntents, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := useCase.Execute(ctx, ListPaymentIntentsUseCaseInput{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.PaymentIntents, 1)
+	assert.Equal(t, domain.PaymentIntentID("pi_3"), second.PaymentIntents[0].ID)
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestListPaymentIntentsUseCase_ShouldRejectGarbageCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	useCase := NewListPaymentIntentsUseCase(repo)
+
+	for _, cursor := range []repository.PaymentIntentCursor{"!!!", "bm8tc2VwYXJhdG9y", "eHxwaV8x"} {
+		_, err := useCase.Execute(ctx, ListPaymentIntentsUseCaseInput{Cursor: cursor})
+		assert.ErrorIs(t, err, domain.ErrInvalidArgument, string(cursor))
+	}
+}
+
+func seedGeneratedPaymentIntent(
+	t *testing.T,
+	ctx context.Context,
+	repo *iarepo.InMemoryPaymentIntentRepository,
+	id domain.PaymentIntentID,
+	businessID domain.BusinessID,
+	createdAt time.Time,
+) domain.PaymentIntentRequiresPaymentMethodType {
+	t.Helper()
+
+	event, aggregate, err := domain.GeneratePaymentIntent(
+		id,
+		domain.Cart{
+			BusinessID: businessID,
+			CartID:     domain.CartID("cart_" + string(id)),
+			Items:      domain.CartItems{{ItemID: "item_123", Price: 120}},
+		},
+		domain.PaymentMethodTypes{domain.PaymentMethodTypeCard},
+		createdAt,
+	)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+
+	return aggregate.(domain.PaymentIntentRequiresPaymentMethodType)
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/converter"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+const (
+	paymentIntentModelSequences = 300
+	paymentIntentModelSteps     = 40
+)
+
+// scriptedPaymentMethodProvider answers each call with whatever the current step scripted.
+type scriptedPaymentMethodProvider struct {
+	fakePaymentMethodProvider
+	next domain.PaymentConfirmationNext
+}
+
+func (s *scriptedPaymentMethodProvider) ConfirmPaymentMethod(context.Context, service.PaymentConfirmationRequest) (service.PaymentConfirmationResult, error) {
+	if s.confirmErr != nil {
+		return service.PaymentConfirmationResult{}, s.confirmErr
+	}
+	return service.PaymentConfirmationResult{NextStatus: s.next}, nil
+}
+
+type paymentIntentModelOperation struct {
+	name string
+	// weight を小さくした操作ほど選ばれにくい。cancel が多いとすぐ終端に達してしまう
+	weight int
+	run    func(ctx context.Context, rng *rand.Rand, id domain.PaymentIntentID) error
+}
+
+func TestPaymentIntent_ModelBasedRandomSequences(t *testing.T) {
+	for seed := range uint64(paymentIntentModelSequences) {
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) {
+			runPaymentIntentModel(t, seed)
+		})
+	}
+}
+
+func runPaymentIntentModel(t *testing.T, seed uint64) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewPCG(seed, seed))
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	provider := &scriptedPaymentMethodProvider{}
+	providers := paymentProvidersOf(provider)
+	clock := iasvc.NewFakeClock(seedTime)
+
+	selectUseCase := NewSelectPaymentMethodUseCase(repo, clock)
+	provideUseCase := NewProvidePaymentMethodUseCase(repo)
+	confirmUseCase := NewConfirmPaymentIntentUseCase(repo, providers, domain.PaymentAttemptPolicy{MaxFailedAttempts: 3}, clock)
+	handleActionUseCase := NewHandlePaymentActionResultUseCase(repo, clock)
+	captureUseCase := NewCapturePaymentIntentUseCase(repo, providers, domain.PaymentOverCapturePolicy{}, domain.PaymentCaptureDeadlinePolicy{}, clock)
+	handleSucceededUseCase := NewHandlePaymentSucceededUseCase(repo)
+	cancelUseCase := NewCancelPaymentIntentUseCase(repo, providers)
+
+	providerErr := func(rng *rand.Rand) error {
+		switch rng.IntN(6) {
+		case 0:
+			return errors.New("provider unavailable")
+		case 1:
+			return &service.PaymentDeclineError{
+				Decline: domain.PaymentDecline{Code: "card_declined", Category: domain.PaymentDeclineCategoryDoNotHonor},
+				Err:     errors.New("declined"),
+			}
+		default:
+			return nil
+		}
+	}
+
+	operations := []paymentIntentModelOperation{
+		{name: "select", weight: 6, run: func(ctx context.Context, rng *rand.Rand, id domain.PaymentIntentID) error {
+			_, err := selectUseCase.Execute(ctx, SelectPaymentMethodUseCaseInput{
+				PaymentIntentID:   id,
+				PaymentMethodType: domain.PaymentMethodTypeCard,
+			})
+			return err
+		}},
+		{name: "provide", weight: 6, run: func(ctx context.Context, rng *rand.Rand, id domain.PaymentIntentID) error {
+			captureMethod := domain.PaymentCaptureMethodAutomatic
+			if rng.IntN(2) == 0 {
+				captureMethod = domain.PaymentCaptureMethodManual
+			}
+			_, err := provideUseCase.Execute(ctx, ProvidePaymentMethodUseCaseInput{
+				PaymentIntentID: id,
+				PaymentMethod: domain.PaymentMethod{
+					PaymentMethodType: domain.PaymentMethodTypeCard,
+					Card:              &domain.PaymentMethodCard{Number: "4242424242424242", ExpYear: 25, ExpMonth: 12},
+				},
+				CaptureMethod: captureMethod,
+			})
+			return err
+		}},
+		{name: "confirm", weight: 6, run: func(ctx context.Context, rng *rand.Rand, id domain.PaymentIntentID) error {
+			nexts := []domain.PaymentConfirmationNext{
+				domain.PaymentConfirmationNextProcessing,
+				domain.PaymentConfirmationNextRequiresAction,
+				domain.PaymentConfirmationNextRequiresCapture,
+			}
+			provider.next = nexts[rng.IntN(len(nexts))]
+			provider.confirmErr = providerErr(rng)
+			_, err := confirmUseCase.Execute(ctx, ConfirmPaymentIntentUseCaseInput{PaymentIntentID: id})
+			return err
+		}},
+		{name: "handle_action", weight: 6, run: func(ctx context.Context, rng *rand.Rand, id domain.PaymentIntentID) error {
+			_, err := handleActionUseCase.Execute(ctx, HandlePaymentActionResultUseCaseInput{PaymentIntentID: id})
+			return err
+		}},
+		{name: "capture", weight: 6, run: func(ctx context.Context, rng *rand.Rand, id domain.PaymentIntentID) error {
+			provider.captureErr = providerErr(rng)
+			input := CapturePaymentIntentUseCaseInput{PaymentIntentID: id}
+			if rng.IntN(2) == 0 {
+				input.AmountToCapture = domain.Money(1 + rng.IntN(60))
+				input.MultiCapture = rng.IntN(2) == 0
+			}
+			_, err := captureUseCase.Execute(ctx, input)
+			return err
+		}},
+		{name: "handle_succeeded", weight: 6, run: func(ctx context.Context, rng *rand.Rand, id domain.PaymentIntentID) error {
+			_, err := handleSucceededUseCase.Execute(ctx, HandlePaymentSucceededUseCaseInput{PaymentIntentID: id})
+			return err
+		}},
+		{name: "cancel", weight: 1, run: func(ctx context.Context, rng *rand.Rand, id domain.PaymentIntentID) error {
+			provider.voidErr = nil
+			if rng.IntN(4) == 0 {
+				provider.voidErr = errors.New("void failed")
+			}
+			_, err := cancelUseCase.Execute(ctx, CancelPaymentIntentUseCaseInput{
+				PaymentIntentID: id,
+				Reason:          domain.PaymentCancellationReasonRequestedByCustomer,
+			})
+			return err
+		}},
+	}
+
+	event, aggregate, err := domain.GeneratePaymentIntent(
+		"pi_model",
+		domain.Cart{BusinessID: "biz_model", CartID: "cart_model", Items: domain.CartItems{{ItemID: "item_model", Price: 120}}},
+		domain.PaymentMethodTypes{domain.PaymentMethodTypeCard},
+		seedTime,
+	)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+
+	previous, err := converter.ToPaymentIntentView(aggregate)
+	require.NoError(t, err)
+	history := []string{}
+
+	for range paymentIntentModelSteps {
+		operation := pickPaymentIntentModelOperation(rng, operations)
+		history = append(history, operation.name)
+		eventsBefore := len(repo.Events())
+
+		// 不正な操作はエラーになってよいが、集約を壊してはいけない
+		_ = operation.run(ctx, rng, previous.ID)
+
+		latest, err := repo.FindBy(ctx, previous.ID)
+		require.NoError(t, err)
+		current, err := converter.ToPaymentIntentView(*latest)
+		require.NoError(t, err)
+
+		written := len(repo.Events()) - eventsBefore
+		require.LessOrEqual(t, written, 1, "history: %v", history)
+		require.Equal(t, previous.SeqNr+uint64(written), current.SeqNr, "SeqNr must increment once per saved event; history: %v", history)
+
+		require.Equal(t, previous.Amount, current.Amount, "amount changed; history: %v", history)
+		if current.Status == string(domain.PaymentIntentStatusProcessing) || current.Status == string(domain.PaymentIntentStatusSucceeded) {
+			require.LessOrEqual(t, current.AmountCaptured, current.Amount, "history: %v", history)
+		}
+
+		if isTerminalPaymentIntentStatus(previous.Status) {
+			require.Zero(t, written, "terminal intent was modified; history: %v", history)
+			require.Equal(t, previous.Status, current.Status, "history: %v", history)
+		}
+		if written == 1 && previous.Status != current.Status {
+			requireDeclaredPaymentIntentTransition(t, previous.Status, current.Status, history)
+		}
+
+		// イベント列から集約を復元できることはドメインのテストで確かめている。ここでは欠番がないことだけを見る
+		require.Len(t, repo.Events(), int(current.SeqNr), "event stream has gaps; history: %v", history)
+
+		previous = current
+	}
+}
+
+func pickPaymentIntentModelOperation(rng *rand.Rand, operations []paymentIntentModelOperation) paymentIntentModelOperation {
+	total := 0
+	for _, operation := range operations {
+		total += operation.weight
+	}
+	n := rng.IntN(total)
+	for _, operation := range operations {
+		if n < operation.weight {
+			return operation
+		}
+		n -= operation.weight
+	}
+	panic("unreachable")
+}
+
+func isTerminalPaymentIntentStatus(status string) bool {
+	return status == string(domain.PaymentIntentStatusSucceeded) || status == string(domain.PaymentIntentStatusCanceled)
+}
+
+func requireDeclaredPaymentIntentTransition(t *testing.T, from, to string, history []string) {
+	t.Helper()
+
+	for _, transition := range domain.PaymentIntentTransitions() {
+		if string(transition.From) != from {
+			continue
+		}
+		for _, target := range transition.To {
+			if string(target) == to {
+				return
+			}
+		}
+	}
+	assert.Failf(t, "undeclared transition", "%s -> %s is not in the transition table; history: %v", from, to, history)
+	t.FailNow()
+}
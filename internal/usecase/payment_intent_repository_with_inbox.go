@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+)
+
+type paymentIntentRepositoryWithInbox struct {
+	repository.PaymentIntentRepository
+	replay ReplayPendingProviderEventsUseCase
+}
+
+// NewPaymentIntentRepositoryWithInbox wraps paymentIntentRepository so that the provider events parked for an intent
+// are replayed right after the intent is saved. Give it to the use cases that drive intents from our side; the
+// handlers behind replay must use the unwrapped repository.
+func NewPaymentIntentRepositoryWithInbox(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	replay ReplayPendingProviderEventsUseCase,
+) repository.PaymentIntentRepository {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if replay == nil {
+		panic("replay is nil")
+	}
+	return &paymentIntentRepositoryWithInbox{
+		PaymentIntentRepository: paymentIntentRepository,
+		replay:                  replay,
+	}
+}
+
+func (r *paymentIntentRepositoryWithInbox) Save(ctx context.Context, event domain.PaymentIntentEvent, aggregate domain.PaymentIntent) error {
+	if err := r.PaymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return err
+	}
+
+	// 保存は成功しているので、再生の失敗は呼び出し元に返さない。通知は保留されたまま次の保存で再試行される
+	id := domain.PaymentIntentIDOf(event)
+	if _, err := r.replay.Execute(ctx, ReplayPendingProviderEventsUseCaseInput{PaymentIntentID: id}); err != nil {
+		slog.WarnContext(ctx, "replay parked provider events failed", "payment_intent_id", id, "error", err)
+	}
+	return nil
+}
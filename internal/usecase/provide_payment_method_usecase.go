@@ -2,7 +2,7 @@ package usecase
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
@@ -40,26 +40,25 @@ func NewProvidePaymentMethodUseCase(paymentIntentRepository repository.PaymentIn
 }
 
 func (i ProvidePaymentMethodUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.PaymentIntentID)
-	contract.AssertValidatable(i.PaymentMethod)
-	contract.AssertValidatable(i.CaptureMethod)
-	return nil
+	return contract.Validate(i.PaymentIntentID, i.PaymentMethod, i.CaptureMethod)
 }
 
 func (u *providePaymentMethodUseCase) Execute(ctx context.Context, input ProvidePaymentMethodUseCaseInput) (*ProvidePaymentMethodUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
 	if err != nil {
 		return nil, err
 	}
 	if paymentIntent == nil {
-		return nil, errors.New("payment intent not found")
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
 	}
 
 	intent, ok := (*paymentIntent).(domain.PaymentIntentRequiresPaymentMethod)
 	if !ok {
-		return nil, errors.New("payment intent not ready for payment method")
+		return nil, fmt.Errorf("%w: payment intent not ready for payment method", domain.ErrInvalidStateTransition)
 	}
 
 	event, aggregate, err := intent.RequireConfirmation(input.PaymentMethod, input.CaptureMethod)
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	RefundPaymentIntentUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+		// Amount を省略した場合は返金可能な残額をすべて返金する
+		Amount domain.Money
+	}
+
+	RefundPaymentIntentUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		RefundID        domain.PaymentRefundID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	RefundPaymentIntentUseCase interface {
+		Execute(context.Context, RefundPaymentIntentUseCaseInput) (*RefundPaymentIntentUseCaseOutput, error)
+	}
+
+	refundPaymentIntentUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+		refundIDGenerator       service.RefundIDGenerator
+		paymentProviders        service.PaymentMethodProviderRegistry
+	}
+)
+
+func NewRefundPaymentIntentUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	refundIDGenerator service.RefundIDGenerator,
+	paymentProviders service.PaymentMethodProviderRegistry,
+) RefundPaymentIntentUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if refundIDGenerator == nil {
+		panic("refundIDGenerator is nil")
+	}
+	if paymentProviders == nil {
+		panic("paymentProviders is nil")
+	}
+	return &refundPaymentIntentUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+		refundIDGenerator:       refundIDGenerator,
+		paymentProviders:        paymentProviders,
+	}
+}
+
+func (i RefundPaymentIntentUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID)
+}
+
+func (u *refundPaymentIntentUseCase) Execute(ctx context.Context, input RefundPaymentIntentUseCaseInput) (*RefundPaymentIntentUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	intent, ok := (*paymentIntent).(domain.PaymentIntentSucceeded)
+	if !ok {
+		return nil, fmt.Errorf("%w: payment intent not ready for refund", domain.ErrInvalidStateTransition)
+	}
+
+	paymentProvider, err := u.paymentProviders.ProviderFor(intent.BusinessID, intent.PaymentMethod.PaymentMethodType)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := input.Amount
+	if amount == 0 {
+		amount = intent.AmountRefundable()
+	}
+	if amount == 0 {
+		return nil, fmt.Errorf("%w: payment intent has nothing left to refund", domain.ErrInvalidStateTransition)
+	}
+
+	refundID, err := u.refundIDGenerator.GenerateID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	event, aggregate, err := intent.RequestRefund(refundID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	pending := aggregate.(domain.PaymentIntentSucceeded)
+
+	// プロバイダが受け付けても返金は確定しない。結果は refund_succeeded / refund_failed の Webhook で反映する
+	if err := paymentProvider.RefundPayment(ctx, service.PaymentRefundRequest{
+		Intent:   pending,
+		RefundID: refundID,
+		Amount:   amount,
+	}); err != nil {
+		event, aggregate, failErr := pending.FailRefund(refundID, domain.PaymentFailureReasonRefundFailed)
+		if failErr != nil {
+			return nil, failErr
+		}
+		if saveErr := u.paymentIntentRepository.Save(ctx, event, aggregate); saveErr != nil {
+			return nil, saveErr
+		}
+
+		return &RefundPaymentIntentUseCaseOutput{
+			PaymentIntentID: input.PaymentIntentID,
+			RefundID:        refundID,
+			PaymentIntent:   aggregate,
+		}, fmt.Errorf("refund payment failed: %w", err)
+	}
+
+	return &RefundPaymentIntentUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		RefundID:        refundID,
+		PaymentIntent:   pending,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+)
+
+func TestRefundPaymentIntentUseCase_ShouldAllowPartialRefundsUpToCapturedAmount(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	refundIDGenerator := &iasvc.FakeRefundIDGenerator{NextID: "re_1"}
+
+	intent := seedPaymentIntentSucceeded(t, ctx, repo)
+	useCase := NewRefundPaymentIntentUseCase(repo, refundIDGenerator, paymentProvidersOf(&fakePaymentMethodProvider{}))
+
+	output, err := useCase.Execute(ctx, RefundPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		Amount:          domain.Money(20),
+	})
+	require.NoError(t, err)
+	result := output.PaymentIntent.(domain.PaymentIntentSucceeded)
+	// プロバイダが受け付けただけなので、Webhook が届くまで返金は保留のまま
+	require.Len(t, result.Refunds, 1)
+	assert.Equal(t, domain.PaymentRefundStatusPending, result.Refunds[0].Status)
+	assert.Equal(t, domain.Money(0), result.Refunds.AmountRefunded())
+	assert.Equal(t, domain.Money(100), result.AmountRefundable())
+
+	refundIDGenerator.NextID = "re_2"
+	output, err = useCase.Execute(ctx, RefundPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+	})
+	require.NoError(t, err)
+	result = output.PaymentIntent.(domain.PaymentIntentSucceeded)
+	assert.Equal(t, domain.Money(0), result.AmountRefundable())
+	assert.Len(t, result.Refunds, 2)
+
+	refundIDGenerator.NextID = "re_3"
+	_, err = useCase.Execute(ctx, RefundPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+		Amount:          domain.Money(1),
+	})
+	require.Error(t, err)
+}
+
+func TestRefundPaymentIntentUseCase_ShouldRecordFailedRefund(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+
+	intent := seedPaymentIntentSucceeded(t, ctx, repo)
+	useCase := NewRefundPaymentIntentUseCase(
+		repo,
+		iasvc.NewFakeRefundIDGenerator("re_1"),
+		paymentProvidersOf(&fakePaymentMethodProvider{refundErr: errors.New("refund rejected")}),
+	)
+
+	output, err := useCase.Execute(ctx, RefundPaymentIntentUseCaseInput{
+		PaymentIntentID: intent.ID,
+	})
+
+	require.Error(t, err)
+	require.NotNil(t, output)
+
+	result := output.PaymentIntent.(domain.PaymentIntentSucceeded)
+	require.Len(t, result.Refunds, 1)
+	assert.Equal(t, domain.PaymentRefundStatusFailed, result.Refunds[0].Status)
+	assert.Equal(t, domain.PaymentFailureReasonRefundFailed, result.Refunds[0].FailureReason)
+	assert.Equal(t, intent.Amount, result.AmountRefundable())
+}
+
+func seedPaymentIntentSucceeded(
+	t *testing.T,
+	ctx context.Context,
+	repo *iarepo.InMemoryPaymentIntentRepository,
+) domain.PaymentIntentSucceeded {
+	t.Helper()
+
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	event, processing, err := confirmation.StartProcessing()
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, processing))
+
+	event, succeeded, err := processing.Complete()
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, succeeded))
+
+	return succeeded.(domain.PaymentIntentSucceeded)
+}
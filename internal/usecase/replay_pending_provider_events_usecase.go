@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	ReplayPendingProviderEventsUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+	}
+
+	ReplayPendingProviderEventsUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		// PaymentIntent is nil when nothing was replayed.
+		PaymentIntent domain.PaymentIntent
+		Replayed      []domain.ProviderEventID
+	}
+
+	ReplayPendingProviderEventsUseCase interface {
+		Execute(context.Context, ReplayPendingProviderEventsUseCaseInput) (*ReplayPendingProviderEventsUseCaseOutput, error)
+	}
+
+	replayPendingProviderEventsUseCase struct {
+		applier *providerEventApplier
+	}
+)
+
+func NewReplayPendingProviderEventsUseCase(
+	providerEventRepository repository.ProviderEventRepository,
+	pendingProviderEventRepository repository.PendingProviderEventRepository,
+	paymentIntentRepository repository.PaymentIntentRepository,
+	handlers PaymentIntentEventHandlers,
+	inboxPolicy domain.ProviderEventInboxPolicy,
+	clock service.Clock,
+) ReplayPendingProviderEventsUseCase {
+	return &replayPendingProviderEventsUseCase{
+		applier: newProviderEventApplier(
+			providerEventRepository,
+			pendingProviderEventRepository,
+			paymentIntentRepository,
+			handlers,
+			inboxPolicy,
+			clock,
+		),
+	}
+}
+
+func (i ReplayPendingProviderEventsUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID)
+}
+
+func (u *replayPendingProviderEventsUseCase) Execute(ctx context.Context, input ReplayPendingProviderEventsUseCaseInput) (*ReplayPendingProviderEventsUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	replayed, latest, err := u.applier.replayPending(ctx, input.PaymentIntentID)
+	return &ReplayPendingProviderEventsUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   latest,
+		Replayed:        replayed,
+	}, err
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	ReselectPaymentMethodTypeUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+	}
+
+	ReselectPaymentMethodTypeUseCaseOutput struct {
+		PaymentIntentID    domain.PaymentIntentID
+		PaymentIntent      domain.PaymentIntent
+		PaymentMethodTypes domain.PaymentMethodTypes
+	}
+
+	ReselectPaymentMethodTypeUseCase interface {
+		Execute(context.Context, ReselectPaymentMethodTypeUseCaseInput) (*ReselectPaymentMethodTypeUseCaseOutput, error)
+	}
+
+	reselectPaymentMethodTypeUseCase struct {
+		paymentIntentRepository repository.PaymentIntentRepository
+		businessRepository      repository.BusinessRepository
+		clock                   service.Clock
+	}
+)
+
+func NewReselectPaymentMethodTypeUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	businessRepository repository.BusinessRepository,
+	clock service.Clock,
+) ReselectPaymentMethodTypeUseCase {
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	if businessRepository == nil {
+		panic("businessRepository is nil")
+	}
+	if clock == nil {
+		panic("clock is nil")
+	}
+	return &reselectPaymentMethodTypeUseCase{
+		paymentIntentRepository: paymentIntentRepository,
+		businessRepository:      businessRepository,
+		clock:                   clock,
+	}
+}
+
+func (i ReselectPaymentMethodTypeUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID)
+}
+
+func (u *reselectPaymentMethodTypeUseCase) Execute(ctx context.Context, input ReselectPaymentMethodTypeUseCaseInput) (*ReselectPaymentMethodTypeUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	intent, ok := (*paymentIntent).(domain.PaymentIntentRequiresPaymentMethod)
+	if !ok {
+		return nil, fmt.Errorf("%w: payment intent is not in requires_payment_method state", domain.ErrInvalidStateTransition)
+	}
+
+	// 選び直せるのは現時点で事業者が許可している決済手段に限る
+	business, err := u.businessRepository.FindBy(ctx, intent.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, domain.NewBusinessNotFoundError(intent.BusinessID)
+	}
+
+	event, aggregate, err := intent.ReselectPaymentMethodType(business.PaymentMethodTypes, u.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	return &ReselectPaymentMethodTypeUseCaseOutput{
+		PaymentIntentID:    input.PaymentIntentID,
+		PaymentIntent:      aggregate,
+		PaymentMethodTypes: business.PaymentMethodTypes,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+)
+
+func TestReselectPaymentMethodTypeUseCase_ShouldAllowSwitchingAfterRetryableFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	businessRepo := iarepo.NewInMemoryBusinessRepository()
+
+	paymentMethodTypes := domain.PaymentMethodTypes{domain.PaymentMethodTypeCard, domain.PaymentMethodTypePayPay}
+	require.NoError(t, businessRepo.Save(
+		ctx,
+		domain.NewBusinessInitializedEvent("biz_fail_test", 1, "Test Business", paymentMethodTypes),
+		domain.Business{ID: "biz_fail_test", Name: "Test Business", PaymentMethodTypes: paymentMethodTypes},
+	))
+
+	confirmation := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	event, aggregate, err := confirmation.FailConfirmation(domain.PaymentDecline{Retryable: true}, seedTime, domain.PaymentAttemptPolicy{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+
+	useCase := NewReselectPaymentMethodTypeUseCase(repo, businessRepo, iasvc.NewFakeClock(seedTime))
+	output, err := useCase.Execute(ctx, ReselectPaymentMethodTypeUseCaseInput{PaymentIntentID: confirmation.ID})
+	require.NoError(t, err)
+	assert.Equal(t, paymentMethodTypes, output.PaymentMethodTypes)
+
+	reselected, ok := output.PaymentIntent.(domain.PaymentIntentRequiresPaymentMethodType)
+	require.True(t, ok)
+	require.Len(t, reselected.Attempts, 1)
+	assert.Equal(t, domain.PaymentMethodTypeCard, reselected.Attempts[0].PaymentMethodType)
+	assert.Equal(t, domain.PaymentFailureReasonConfirmationFailed, reselected.Attempts[0].FailureReason)
+
+	_, next, err := reselected.RequirePaymentMethod(domain.PaymentMethodTypePayPay, seedTime)
+	require.NoError(t, err)
+	method, ok := next.(domain.PaymentIntentRequiresPaymentMethod)
+	require.True(t, ok)
+	assert.Equal(t, domain.PaymentMethodTypePayPay, method.PaymentMethodType)
+	assert.Len(t, method.Attempts, 1)
+}
+
+func TestReselectPaymentMethodTypeUseCase_ShouldRejectWithoutPriorFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	businessRepo := iarepo.NewInMemoryBusinessRepository()
+
+	intent := seedGeneratedPaymentIntent(t, ctx, repo, "pi_1", "biz_a", seedTime)
+	event, aggregate, err := intent.RequirePaymentMethod(domain.PaymentMethodTypeCard, seedTime)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, event, aggregate))
+	paymentMethodTypes := domain.PaymentMethodTypes{domain.PaymentMethodTypeCard}
+	require.NoError(t, businessRepo.Save(
+		ctx,
+		domain.NewBusinessInitializedEvent("biz_a", 1, "Test Business", paymentMethodTypes),
+		domain.Business{ID: "biz_a", Name: "Test Business", PaymentMethodTypes: paymentMethodTypes},
+	))
+
+	useCase := NewReselectPaymentMethodTypeUseCase(repo, businessRepo, iasvc.NewFakeClock(seedTime))
+	_, err = useCase.Execute(ctx, ReselectPaymentMethodTypeUseCaseInput{PaymentIntentID: intent.ID})
+	require.Error(t, err)
+}
@@ -2,11 +2,12 @@ package usecase
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
 	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
 )
 
 type (
@@ -26,41 +27,49 @@ type (
 
 	selectPaymentMethodUseCase struct {
 		paymentIntentRepository repository.PaymentIntentRepository
+		clock                   service.Clock
 	}
 )
 
-func NewSelectPaymentMethodUseCase(paymentIntentRepository repository.PaymentIntentRepository) SelectPaymentMethodUseCase {
+func NewSelectPaymentMethodUseCase(
+	paymentIntentRepository repository.PaymentIntentRepository,
+	clock service.Clock,
+) SelectPaymentMethodUseCase {
 	if paymentIntentRepository == nil {
 		panic("paymentIntentRepository is nil")
 	}
+	if clock == nil {
+		panic("clock is nil")
+	}
 	return &selectPaymentMethodUseCase{
 		paymentIntentRepository: paymentIntentRepository,
+		clock:                   clock,
 	}
 }
 
 func (i SelectPaymentMethodUseCaseInput) Validate() error {
-	contract.AssertValidatable(i.PaymentIntentID)
-	contract.AssertValidatable(i.PaymentMethodType)
-	return nil
+	return contract.Validate(i.PaymentIntentID, i.PaymentMethodType)
 }
 
 func (u *selectPaymentMethodUseCase) Execute(ctx context.Context, input SelectPaymentMethodUseCaseInput) (*SelectPaymentMethodUseCaseOutput, error) {
-	contract.AssertValidatable(input)
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
 
 	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
 	if err != nil {
 		return nil, err
 	}
 	if paymentIntent == nil {
-		return nil, errors.New("payment intent not found")
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
 	}
 
 	intent, ok := (*paymentIntent).(domain.PaymentIntentRequiresPaymentMethodType)
 	if !ok {
-		return nil, errors.New("payment intent not ready for payment method selection")
+		return nil, fmt.Errorf("%w: payment intent not ready for payment method selection", domain.ErrInvalidStateTransition)
 	}
 
-	event, aggregate, err := intent.RequirePaymentMethod(input.PaymentMethodType)
+	event, aggregate, err := intent.RequirePaymentMethod(input.PaymentMethodType, u.clock.Now())
 	if err != nil {
 		return nil, err
 	}
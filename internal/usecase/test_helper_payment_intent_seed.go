@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -10,6 +11,8 @@ import (
 	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
 )
 
+var seedTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
 func seedPaymentIntentRequiresConfirmation(
 	t *testing.T,
 	ctx context.Context,
@@ -20,8 +23,9 @@ func seedPaymentIntentRequiresConfirmation(
 
 	paymentIntentID := domain.PaymentIntentID("pi_fail_test")
 	paymentMethodType := domain.PaymentMethodTypeCard
-	amount := domain.NewMoney(120)
-	paymentMethod := domain.NewPaymentMethod(
+	amount, err := domain.NewMoney(120)
+	require.NoError(t, err)
+	paymentMethod, err := domain.NewPaymentMethod(
 		paymentMethodType,
 		&domain.PaymentMethodCard{
 			Number:   "4242424242424242",
@@ -30,12 +34,22 @@ func seedPaymentIntentRequiresConfirmation(
 		},
 		nil,
 	)
+	require.NoError(t, err)
+	items, err := domain.NewCartItems(domain.CartItem{ItemID: "item_fail_test", Price: domain.ItemPrice(amount)})
+	require.NoError(t, err)
+	cart, err := domain.NewCart("biz_fail_test", "cart_fail_test", items)
+	require.NoError(t, err)
 
-	event, aggregate, err := domain.GeneratePaymentIntent(paymentIntentID, domain.PaymentMethodTypes{paymentMethodType}, amount)
+	event, aggregate, err := domain.GeneratePaymentIntent(
+		paymentIntentID,
+		cart,
+		domain.PaymentMethodTypes{paymentMethodType},
+		seedTime,
+	)
 	require.NoError(t, err)
 	require.NoError(t, repo.Save(ctx, event, aggregate))
 
-	event, aggregate, err = aggregate.(domain.PaymentIntentRequiresPaymentMethodType).RequirePaymentMethod(paymentMethodType)
+	event, aggregate, err = aggregate.(domain.PaymentIntentRequiresPaymentMethodType).RequirePaymentMethod(paymentMethodType, seedTime)
 	require.NoError(t, err)
 	require.NoError(t, repo.Save(ctx, event, aggregate))
 
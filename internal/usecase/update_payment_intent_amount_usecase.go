@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/lib/contract"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/repository"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+type (
+	UpdatePaymentIntentAmountUseCaseInput struct {
+		PaymentIntentID domain.PaymentIntentID
+		CartToken       service.SignedToken
+	}
+
+	UpdatePaymentIntentAmountUseCaseOutput struct {
+		PaymentIntentID domain.PaymentIntentID
+		PaymentIntent   domain.PaymentIntent
+	}
+
+	UpdatePaymentIntentAmountUseCase interface {
+		Execute(context.Context, UpdatePaymentIntentAmountUseCaseInput) (*UpdatePaymentIntentAmountUseCaseOutput, error)
+	}
+
+	updatePaymentIntentAmountUseCase struct {
+		tokenService            service.TokenService
+		paymentIntentRepository repository.PaymentIntentRepository
+	}
+)
+
+func NewUpdatePaymentIntentAmountUseCase(
+	tokenService service.TokenService,
+	paymentIntentRepository repository.PaymentIntentRepository,
+) UpdatePaymentIntentAmountUseCase {
+	if tokenService == nil {
+		panic("tokenService is nil")
+	}
+	if paymentIntentRepository == nil {
+		panic("paymentIntentRepository is nil")
+	}
+	return &updatePaymentIntentAmountUseCase{
+		tokenService:            tokenService,
+		paymentIntentRepository: paymentIntentRepository,
+	}
+}
+
+func (i UpdatePaymentIntentAmountUseCaseInput) Validate() error {
+	return contract.Validate(i.PaymentIntentID, i.CartToken)
+}
+
+func (u *updatePaymentIntentAmountUseCase) Execute(ctx context.Context, input UpdatePaymentIntentAmountUseCaseInput) (*UpdatePaymentIntentAmountUseCaseOutput, error) {
+	if err := contract.Validate(input); err != nil {
+		return nil, err
+	}
+
+	cart, err := u.tokenService.ParseCartToken(ctx, input.CartToken)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentIntent, err := u.paymentIntentRepository.FindBy(ctx, input.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentIntent == nil {
+		return nil, domain.NewPaymentIntentNotFoundError(input.PaymentIntentID)
+	}
+
+	var (
+		event     domain.PaymentIntentEvent
+		aggregate domain.PaymentIntent
+	)
+
+	switch intent := (*paymentIntent).(type) {
+	case domain.PaymentIntentRequiresPaymentMethodType:
+		event, aggregate, err = intent.UpdateAmount(cart)
+	case domain.PaymentIntentRequiresPaymentMethod:
+		event, aggregate, err = intent.UpdateAmount(cart)
+	case domain.PaymentIntentRequiresConfirmation:
+		event, aggregate, err = intent.UpdateAmount(cart)
+	default:
+		// UpdateAmount を持たない状態は遷移表にも載っていない
+		return nil, domain.ErrInvalidTransition{From: (*paymentIntent).Status(), Action: domain.PaymentIntentActionUpdateAmount}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.paymentIntentRepository.Save(ctx, event, aggregate); err != nil {
+		return nil, err
+	}
+
+	return &UpdatePaymentIntentAmountUseCaseOutput{
+		PaymentIntentID: input.PaymentIntentID,
+		PaymentIntent:   aggregate,
+	}, nil
+}
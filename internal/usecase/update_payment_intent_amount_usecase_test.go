@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/domain"
+	iarepo "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/repository"
+	iasvc "yoshiyoshifujii/go-capability-token-relay-pattern/internal/interface_adaptor/service"
+	"yoshiyoshifujii/go-capability-token-relay-pattern/internal/service"
+)
+
+func TestUpdatePaymentIntentAmountUseCase_ShouldReplaceAmountFromFreshCartToken(t *testing.T) {
+	ctx := context.Background()
+	repo := iarepo.NewInMemoryPaymentIntentRepository()
+	tokenService := iasvc.NewTokenService([]byte("cart_token_secret"))
+
+	intent := seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic)
+	token, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{
+		Cart: domain.Cart{
+			BusinessID: intent.BusinessID,
+			CartID:     intent.CartID,
+			Items: domain.CartItems{
+				{ItemID: "item_123", Price: 120},
+				{ItemID: "item_456", Price: 30},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	useCase := NewUpdatePaymentIntentAmountUseCase(tokenService, repo)
+	output, err := useCase.Execute(ctx, UpdatePaymentIntentAmountUseCaseInput{
+		PaymentIntentID: intent.ID,
+		CartToken:       token,
+	})
+	require.NoError(t, err)
+
+	result, ok := output.PaymentIntent.(domain.PaymentIntentRequiresConfirmation)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(150), result.Amount)
+	assert.Len(t, result.CartItems, 2)
+	assert.Equal(t, intent.PaymentMethod, result.PaymentMethod)
+
+	events := repo.Events()
+	changed, ok := events[len(events)-1].(domain.PaymentIntentAmountChangedEvent)
+	require.True(t, ok)
+	assert.Equal(t, domain.Money(120), changed.PreviousAmount)
+	assert.Equal(t, domain.Money(150), changed.Amount)
+}
+
+func TestUpdatePaymentIntentAmountUseCase_ShouldRejectInvalidRequests(t *testing.T) {
+	ctx := context.Background()
+	tokenService := iasvc.NewTokenService([]byte("cart_token_secret"))
+
+	tests := []struct {
+		name   string
+		seed   func(*testing.T, *iarepo.InMemoryPaymentIntentRepository) domain.PaymentIntentID
+		cartID domain.CartID
+	}{
+		{
+			name: "other cart",
+			seed: func(t *testing.T, repo *iarepo.InMemoryPaymentIntentRepository) domain.PaymentIntentID {
+				return seedPaymentIntentRequiresConfirmation(t, ctx, repo, domain.PaymentCaptureMethodAutomatic).ID
+			},
+			cartID: "cart_other",
+		},
+		{
+			name: "after confirmation",
+			seed: func(t *testing.T, repo *iarepo.InMemoryPaymentIntentRepository) domain.PaymentIntentID {
+				return seedPaymentIntentRequiresCapture(t, ctx, repo).ID
+			},
+			cartID: "cart_fail_test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := iarepo.NewInMemoryPaymentIntentRepository()
+			id := tt.seed(t, repo)
+			before := len(repo.Events())
+
+			token, err := tokenService.ConfirmCartToken(ctx, service.ConfirmCartTokenInput{
+				Cart: domain.Cart{
+					BusinessID: "biz_fail_test",
+					CartID:     tt.cartID,
+					Items:      domain.CartItems{{ItemID: "item_123", Price: 90}},
+				},
+			})
+			require.NoError(t, err)
+
+			useCase := NewUpdatePaymentIntentAmountUseCase(tokenService, repo)
+			_, err = useCase.Execute(ctx, UpdatePaymentIntentAmountUseCaseInput{
+				PaymentIntentID: id,
+				CartToken:       token,
+			})
+			require.Error(t, err)
+			assert.Len(t, repo.Events(), before)
+		})
+	}
+}